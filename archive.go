@@ -0,0 +1,372 @@
+package gofs
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// CompressionLevel selects the trade-off between speed and size when compressing.
+type CompressionLevel int
+
+const (
+	// CompressionDefault uses the default level of the codec.
+	CompressionDefault CompressionLevel = iota
+	// CompressionNone stores data uncompressed where the format allows it.
+	CompressionNone
+	// CompressionFastest compresses as fast as possible.
+	CompressionFastest
+	// CompressionBest compresses as small as possible.
+	CompressionBest
+)
+
+// ArchiveEntry describes a single entry of an archive file.
+type ArchiveEntry struct {
+	// Name is the slash separated path of the entry inside of the archive.
+	Name    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+	// LinkTarget is the target of symlink entries.
+	LinkTarget string
+}
+
+// IsDir returns true if the entry is a directory.
+func (x ArchiveEntry) IsDir() bool {
+	return x.Mode.IsDir()
+}
+
+// IsSymlink returns true if the entry is a symlink.
+func (x ArchiveEntry) IsSymlink() bool {
+	return x.Mode&os.ModeSymlink != 0
+}
+
+// archiveTargetPath returns the path an archive entry called name is extracted to inside of target.
+// An AssertionError wrapping ErrUnsafeArchivePath is returned for names that would end up outside
+// of target.
+func archiveTargetPath(target Dir, name string) (string, error) {
+	// backslashes are treated as separators too so that archives created on Windows can not escape
+	normalized := strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean(normalized)
+	if normalized == "" || path.IsAbs(normalized) || filepath.VolumeName(name) != "" ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", NewAssertionError(ErrUnsafeArchivePath, name, "archive entry %s would be extracted outside of %s", name, target)
+	}
+	return filepath.Join(target.Path(), filepath.FromSlash(clean)), nil
+}
+
+// isArchiveOf returns true if archive is inside of dir, so it must not be added to itself.
+func isArchiveOf(archive File, dir Dir, p string) bool {
+	return sameFs(archive.fs, dir.fs) && archive.Path() == p
+}
+
+// maxSymlinkHops limits how many symlinks are followed when resolving a path.
+const maxSymlinkHops = 40
+
+// archiveExtractor writes archive entries to a target dir, enforcing the extract options.
+//
+// Entries are never written through symlinks: symlinks of the archive are only created by finish,
+// after all other entries, and entries with a symlink in their path are rejected. A symlink is only
+// kept if it resolves to a path inside of the target dir, taking the symlinks on disk into account.
+type archiveExtractor struct {
+	target  Dir
+	opts    ExtractOptions
+	written int64
+	// links are the symlinks to create in finish, in archive order.
+	links []archiveLink
+	// linkPaths are the paths of links.
+	linkPaths map[string]bool
+	// files are the paths of the regular files written so far.
+	files map[string]bool
+}
+
+type archiveLink struct {
+	path   string
+	target string
+	info   os.FileInfo
+}
+
+func newArchiveExtractor(target Dir, opts ExtractOptions) *archiveExtractor {
+	return &archiveExtractor{
+		target:    target,
+		opts:      opts,
+		linkPaths: make(map[string]bool),
+		files:     make(map[string]bool),
+	}
+}
+
+// checkPath returns an AssertionError wrapping ErrUnsafeArchivePath if a parent of p below the
+// target dir is a symlink on disk or a symlink of the archive. If self is true, p itself is
+// checked too.
+func (x *archiveExtractor) checkPath(p string, self bool) error {
+	rel, err := filepath.Rel(x.target.Path(), p)
+	if err != nil || rel == "." {
+		return err
+	}
+	parts := strings.Split(rel, string(filepath.Separator))
+	if !self {
+		parts = parts[:len(parts)-1]
+	}
+	current := x.target.Path()
+	for _, part := range parts {
+		current = filepath.Join(current, part)
+		if x.linkPaths[current] {
+			return NewAssertionError(ErrUnsafeArchivePath, p, "archive entry %s would be extracted through symlink %s", p, current)
+		}
+		info, err := lstat(x.target.fs, current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return NewAssertionError(ErrUnsafeArchivePath, p, "archive entry %s would be extracted through symlink %s", p, current)
+		}
+	}
+	return nil
+}
+
+func (x *archiveExtractor) ensureDir(p string, perm os.FileMode) error {
+	err := x.checkPath(p, true)
+	if err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = x.opts.DirPermissions
+	}
+	if perm == 0 {
+		perm = 0750
+	}
+	return x.target.fs.MkdirAll(p, perm)
+}
+
+func (x *archiveExtractor) file(p string, info os.FileInfo, open func() (io.ReadCloser, error)) error {
+	fs := x.target.fs
+	err := x.ensureDir(filepath.Dir(p), 0)
+	if err != nil {
+		return err
+	}
+	extract, err := checkOverwrite(fs, p, info, x.opts.Overwrite)
+	if err != nil || !extract {
+		return err
+	}
+	// replace existing symlinks instead of writing to the file they point to
+	if existing, err := lstat(fs, p); err == nil && existing.Mode()&os.ModeSymlink != 0 {
+		err = fs.Remove(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	perm := info.Mode().Perm()
+	if perm == 0 {
+		perm = FileWithFs(p, fs).createPermissions
+	}
+
+	src, err := open()
+	if err != nil {
+		return errors.Annotatef(err, "could not read archive entry for %s", p)
+	}
+	defer src.Close()
+
+	dest, err := fs.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	var r io.Reader = src
+	if x.opts.MaxSize > 0 {
+		r = io.LimitReader(src, x.opts.MaxSize-x.written+1)
+	}
+	n, err := io.Copy(dest, r)
+	closeErr := dest.Close()
+	x.written += n
+	if err == nil && x.opts.MaxSize > 0 && x.written > x.opts.MaxSize {
+		_ = fs.Remove(p)
+		return NewAssertionError(ErrArchiveTooLarge, p, "extracting %s exceeds the limit of %d bytes", p, x.opts.MaxSize)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "could not extract %s", p)
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+	x.files[p] = true
+
+	err = fs.Chmod(p, perm)
+	if err != nil {
+		return err
+	}
+	if info.ModTime().IsZero() {
+		return nil
+	}
+	return fs.Chtimes(p, info.ModTime(), info.ModTime())
+}
+
+// hardlink extracts a hard link to linkedPath as a copy. linkedPath must be a regular file that
+// was extracted from the same archive before.
+func (x *archiveExtractor) hardlink(p string, linkedPath string) error {
+	err := x.checkPath(linkedPath, true)
+	if err != nil {
+		return err
+	}
+	linkedInfo, err := lstat(x.target.fs, linkedPath)
+	if err != nil {
+		return errors.Annotatef(err, "could not resolve hard link %s to %s", p, linkedPath)
+	}
+	if !x.files[linkedPath] || !linkedInfo.Mode().IsRegular() {
+		return NewAssertionError(ErrUnsafeArchivePath, p, "hard link %s must point to a file extracted from the archive, but points to %s", p, linkedPath)
+	}
+	return x.file(p, linkedInfo, func() (io.ReadCloser, error) {
+		return x.target.fs.Open(linkedPath)
+	})
+}
+
+// symlink records a symlink to be created by finish.
+func (x *archiveExtractor) symlink(p string, linkTarget string, info os.FileInfo) error {
+	if _, ok := x.target.fs.(afero.Linker); !ok {
+		return errors.NotSupportedf("creating symlinks on %T", x.target.fs)
+	}
+	err := x.checkPath(p, false)
+	if err != nil {
+		return err
+	}
+	x.links = append(x.links, archiveLink{path: p, target: linkTarget, info: info})
+	x.linkPaths[p] = true
+	return nil
+}
+
+// finish creates the recorded symlinks. Symlinks that would resolve outside of the target dir are
+// rejected with an AssertionError wrapping ErrUnsafeArchivePath, which also removes the symlinks
+// created before.
+func (x *archiveExtractor) finish() error {
+	fs := x.target.fs
+	created := make([]archiveLink, 0, len(x.links))
+	removeCreated := func() {
+		for _, link := range created {
+			_ = fs.Remove(link.path)
+		}
+	}
+
+	for _, link := range x.links {
+		err := x.createSymlink(link)
+		if err != nil {
+			removeCreated()
+			return err
+		}
+		created = append(created, link)
+	}
+
+	// links created later can change where earlier ones resolve to
+	for _, link := range created {
+		inside, err := x.resolvesInside(link.path)
+		if err != nil {
+			removeCreated()
+			return err
+		}
+		if !inside {
+			removeCreated()
+			return NewAssertionError(ErrUnsafeArchivePath, link.path, "symlink %s to %s would point outside of %s", link.path, link.target, x.target)
+		}
+	}
+	return nil
+}
+
+func (x *archiveExtractor) createSymlink(link archiveLink) error {
+	fs := x.target.fs
+	delete(x.linkPaths, link.path)
+	err := x.checkPath(link.path, false)
+	if err != nil {
+		return err
+	}
+	if filepath.IsAbs(link.target) || strings.HasPrefix(link.target, "\\") {
+		return NewAssertionError(ErrUnsafeArchivePath, link.path, "symlink %s to %s would point outside of %s", link.path, link.target, x.target)
+	}
+	inside, err := x.resolvesInside(filepath.Join(filepath.Dir(link.path), filepath.FromSlash(link.target)))
+	if err != nil {
+		return err
+	}
+	if !inside {
+		return NewAssertionError(ErrUnsafeArchivePath, link.path, "symlink %s to %s would point outside of %s", link.path, link.target, x.target)
+	}
+
+	extract, err := checkOverwrite(fs, link.path, link.info, x.opts.Overwrite)
+	if err != nil || !extract {
+		return err
+	}
+	err = fs.Remove(link.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return fs.(afero.Linker).SymlinkIfPossible(link.target, link.path)
+}
+
+// resolvesInside returns true if p stays inside of the target dir when all symlinks in it are
+// resolved. p is lexically cleaned, so it must not contain symlinks outside of the target dir.
+func (x *archiveExtractor) resolvesInside(p string) (bool, error) {
+	root := x.target.Path()
+	if !isPathBelow(p, root) {
+		return false, nil
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false, err
+	}
+
+	current := root
+	pending := strings.Split(rel, string(filepath.Separator))
+	hops := 0
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			if !isPathBelow(current, root) {
+				return false, nil
+			}
+			continue
+		}
+
+		next := filepath.Join(current, part)
+		info, err := lstat(x.target.fs, next)
+		if os.IsNotExist(err) {
+			current = next
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return false, nil
+		}
+		linkTarget, err := readSymlink(x.target.fs, next)
+		if err != nil {
+			return false, err
+		}
+		if filepath.IsAbs(linkTarget) {
+			rel, err = filepath.Rel(root, filepath.Clean(linkTarget))
+			if err != nil || !isPathBelow(linkTarget, root) {
+				return false, nil
+			}
+			current = root
+			linkTarget = rel
+		}
+		pending = append(strings.Split(linkTarget, string(filepath.Separator)), pending...)
+	}
+	return true, nil
+}
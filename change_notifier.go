@@ -0,0 +1,109 @@
+package gofs
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+)
+
+// defaultPollInterval is used to check for changes if no interval is configured.
+const defaultPollInterval = 250 * time.Millisecond
+
+// changeNotifier blocks until paths might have changed. On the OS filesystem it is woken up by
+// inotify (or the platform's equivalent), in addition it polls in the given interval so that it
+// works on every afero backend and with filesystems that do not emit events.
+type changeNotifier struct {
+	watcher  *fsnotify.Watcher
+	interval time.Duration
+	// changed are the paths of the events received since the last call of takeChanged.
+	changed []string
+	// overflow is true if events might have been lost since the last call of takeChanged.
+	overflow bool
+}
+
+func newChangeNotifier(fs afero.Fs, interval time.Duration) *changeNotifier {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	result := &changeNotifier{interval: interval}
+	if _, isOs := fs.(*afero.OsFs); isOs {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			result.watcher = watcher
+		}
+	}
+	return result
+}
+
+// active returns true if the notifier receives events and does not rely on polling only.
+func (x *changeNotifier) active() bool {
+	return x.watcher != nil
+}
+
+// add watches the file or dir at p for changes. Watching dirs includes the files in it. Errors are
+// ignored because polling catches all changes anyway.
+func (x *changeNotifier) add(p string) {
+	if x.watcher == nil {
+		return
+	}
+	_ = x.watcher.Add(p)
+}
+
+// remove stops watching p.
+func (x *changeNotifier) remove(p string) {
+	if x.watcher == nil {
+		return
+	}
+	_ = x.watcher.Remove(p)
+}
+
+// wait blocks until an event is received, the poll interval elapsed or ctx is done. false is
+// returned if ctx is done. The paths of the events are discarded, use waitFor and takeChanged to
+// get them.
+func (x *changeNotifier) wait(ctx context.Context) bool {
+	_, ok := x.waitFor(ctx, x.interval)
+	x.takeChanged()
+	return ok
+}
+
+// waitFor blocks until an event is received, timeout elapsed or ctx is done. It returns if an event
+// was received and false as second value if ctx is done.
+func (x *changeNotifier) waitFor(ctx context.Context, timeout time.Duration) (bool, bool) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if x.watcher != nil {
+		events, errs = x.watcher.Events, x.watcher.Errors
+	}
+	select {
+	case <-ctx.Done():
+		return false, false
+	case event := <-events:
+		x.changed = append(x.changed, event.Name)
+		return true, true
+	case <-errs:
+		x.overflow = true
+		return true, true
+	case <-timer.C:
+		return false, true
+	}
+}
+
+// takeChanged returns and resets the paths of the received events and if events might have been
+// lost.
+func (x *changeNotifier) takeChanged() ([]string, bool) {
+	changed, overflow := x.changed, x.overflow
+	x.changed, x.overflow = nil, false
+	return changed, overflow
+}
+
+func (x *changeNotifier) close() {
+	if x.watcher == nil {
+		return
+	}
+	_ = x.watcher.Close()
+}
@@ -0,0 +1,290 @@
+package gofs
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// ChecksumFormat is the line format of a checksum manifest.
+type ChecksumFormat int
+
+const (
+	// ChecksumFormatGNU is the format of sha256sum, md5sum and friends: "<hex digest>  <path>".
+	ChecksumFormatGNU ChecksumFormat = iota
+	// ChecksumFormatBSD is the tagged format of BSD tools and "sha256sum --tag": "SHA256 (<path>) = <hex digest>".
+	ChecksumFormatBSD
+)
+
+// ChecksumReport is the result of verifying a dir against a checksum manifest. All paths are slash
+// separated and relative to the verified dir.
+type ChecksumReport struct {
+	// Matched are the files whose content matches the manifest.
+	Matched []string
+	// Missing are the files listed in the manifest that do not exist.
+	Missing []string
+	// Mismatched are the files whose content does not match the manifest.
+	Mismatched []string
+	// Unexpected are the files that exist but are not listed in the manifest.
+	Unexpected []string
+}
+
+// Valid returns true if all files listed in the manifest exist and match. Unexpected files are not
+// considered, like sha256sum --check does.
+func (x ChecksumReport) Valid() bool {
+	return len(x.Missing) == 0 && len(x.Mismatched) == 0
+}
+
+// checksumEntry is a single line of a checksum manifest.
+type checksumEntry struct {
+	path   string
+	digest Digest
+}
+
+// WriteChecksumManifest writes the digests of all files below this dir to manifest in the format of
+// sha256sum and friends. Paths are relative to this dir and sorted, the manifest itself is skipped
+// if it is inside of this dir.
+func (x Dir) WriteChecksumManifest(manifest File, algorithm HashAlgorithm) error {
+	return x.WriteChecksumManifestWithFormat(manifest, algorithm, ChecksumFormatGNU)
+}
+
+// WriteChecksumManifestWithFormat writes a checksum manifest like WriteChecksumManifest using the
+// given line format.
+func (x Dir) WriteChecksumManifestWithFormat(manifest File, algorithm HashAlgorithm, format ChecksumFormat) error {
+	files, err := x.checksumFiles(manifest)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, relPath := range files {
+		digest, err := x.MustFileAt(filepath.FromSlash(relPath)).Hash(algorithm)
+		if err != nil {
+			return err
+		}
+		b.WriteString(formatChecksumLine(checksumEntry{path: relPath, digest: digest}, format))
+	}
+	return manifest.SetContentString(b.String())
+}
+
+// VerifyChecksumManifest checks the files below this dir against manifest. Manifests in GNU and BSD
+// format are supported. For GNU manifests, the algorithm is derived from the manifest filename
+// (e.g. "SHA256SUMS" or "release.sha512") or else from the length of the digests.
+func (x Dir) VerifyChecksumManifest(manifest File) (*ChecksumReport, error) {
+	entries, err := parseChecksumManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ChecksumReport{
+		Matched:    make([]string, 0),
+		Missing:    make([]string, 0),
+		Mismatched: make([]string, 0),
+		Unexpected: make([]string, 0),
+	}
+	listed := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		listed[entry.path] = true
+		f := x.MustFileAt(filepath.FromSlash(entry.path))
+		if !f.Exists() {
+			report.Missing = append(report.Missing, entry.path)
+			continue
+		}
+		digest, err := f.Hash(entry.digest.Algorithm())
+		if err != nil {
+			return nil, err
+		}
+		if digest.Equals(entry.digest) {
+			report.Matched = append(report.Matched, entry.path)
+			continue
+		}
+		report.Mismatched = append(report.Mismatched, entry.path)
+	}
+
+	files, err := x.checksumFiles(manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, relPath := range files {
+		if !listed[relPath] {
+			report.Unexpected = append(report.Unexpected, relPath)
+		}
+	}
+	return report, nil
+}
+
+// checksumFiles returns the sorted relative paths of all files below this dir except manifest.
+func (x Dir) checksumFiles(manifest File) ([]string, error) {
+	files := make([]string, 0)
+	err := x.WalkFiles(WalkOptions{}, func(file File) error {
+		if file.Equals(manifest) && sameFs(file.fs, manifest.fs) {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(file.RelativeTo(x)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func formatChecksumLine(entry checksumEntry, format ChecksumFormat) string {
+	if format == ChecksumFormatBSD {
+		return fmt.Sprintf("%s (%s) = %s\n", bsdChecksumTag(entry.digest.Algorithm()), entry.path, entry.digest.Hex())
+	}
+
+	// like coreutils, escape file names with backslashes or line breaks and mark the line with a
+	// leading backslash
+	if strings.ContainsAny(entry.path, "\\\n\r") {
+		escaped := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r").Replace(entry.path)
+		return fmt.Sprintf("\\%s  %s\n", entry.digest.Hex(), escaped)
+	}
+	return fmt.Sprintf("%s  %s\n", entry.digest.Hex(), entry.path)
+}
+
+func bsdChecksumTag(algorithm HashAlgorithm) string {
+	switch algorithm.Name() {
+	case HashBlake2b512.Name():
+		return "BLAKE2b"
+	case HashBlake3.Name():
+		return "BLAKE3"
+	default:
+		return strings.ToUpper(algorithm.Name())
+	}
+}
+
+func parseChecksumManifest(manifest File) ([]checksumEntry, error) {
+	content, err := manifest.ContentString()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]checksumEntry, 0)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseChecksumLine(line, manifest)
+		if err != nil {
+			return nil, errors.Annotatef(err, "%s:%d", manifest, lineNumber)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func parseChecksumLine(line string, manifest File) (checksumEntry, error) {
+	var algorithm HashAlgorithm
+	var hexDigest, relPath string
+
+	if match := bsdChecksumLine.FindStringSubmatch(line); match != nil {
+		relPath, hexDigest = match[2], match[3]
+		var err error
+		algorithm, err = algorithmFromBsdTag(match[1])
+		if err != nil {
+			return checksumEntry{}, err
+		}
+	} else {
+		// GNU format
+		escaped := strings.HasPrefix(line, "\\")
+		line = strings.TrimPrefix(line, "\\")
+		var found bool
+		hexDigest, relPath, found = strings.Cut(line, " ")
+		if !found || relPath == "" || (relPath[0] != ' ' && relPath[0] != '*') {
+			return checksumEntry{}, errors.NotValidf("checksum line %q", line)
+		}
+		// the second char marks text (" ") or binary ("*") mode, both are read the same way
+		relPath = relPath[1:]
+		if escaped {
+			relPath = unescapeChecksumPath(relPath)
+		}
+		algorithm = algorithmForGnuManifest(manifest, hexDigest)
+	}
+
+	digest, err := ParseHexDigest(algorithm, strings.ToLower(hexDigest))
+	if err != nil {
+		return checksumEntry{}, err
+	}
+
+	cleanPath := path.Clean(relPath)
+	if path.IsAbs(cleanPath) || cleanPath == "." || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+		return checksumEntry{}, errors.NotValidf("path %s outside of the verified dir", relPath)
+	}
+	return checksumEntry{path: cleanPath, digest: digest}, nil
+}
+
+var bsdChecksumLine = regexp.MustCompile(`^([A-Za-z0-9-]+) \((.*)\) = ([0-9a-fA-F]+)$`)
+
+func algorithmFromBsdTag(tag string) (HashAlgorithm, error) {
+	switch strings.ToLower(tag) {
+	case "blake2b":
+		return HashBlake2b512, nil
+	case "blake2b-256":
+		return HashBlake2b256, nil
+	default:
+		return HashAlgorithmByName(tag)
+	}
+}
+
+// algorithmForGnuManifest guesses the algorithm of a GNU style manifest from its filename or else
+// from the length of the digest. A filename hint is only used if it matches the length of the
+// digest.
+func algorithmForGnuManifest(manifest File, hexDigest string) HashAlgorithm {
+	filename := strings.ToLower(manifest.Filename())
+	matches := func(algorithm HashAlgorithm) bool {
+		return algorithm.Size()*2 == len(hexDigest)
+	}
+	for _, algorithm := range []HashAlgorithm{HashSha512, HashSha256, HashSha1, HashMd5, HashBlake3, HashCrc32} {
+		if strings.Contains(filename, algorithm.Name()) && matches(algorithm) {
+			return algorithm
+		}
+	}
+	if (strings.Contains(filename, "b2") || strings.Contains(filename, "blake2")) && matches(HashBlake2b512) {
+		return HashBlake2b512
+	}
+
+	switch len(hexDigest) {
+	case 8:
+		return HashCrc32
+	case 32:
+		return HashMd5
+	case 40:
+		return HashSha1
+	case 128:
+		return HashSha512
+	default:
+		return HashSha256
+	}
+}
+
+func unescapeChecksumPath(escaped string) string {
+	var b strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		if escaped[i] != '\\' || i == len(escaped)-1 {
+			b.WriteByte(escaped[i])
+			continue
+		}
+		i++
+		switch escaped[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(escaped[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDir_WriteChecksumManifest(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs("/tmp/release", afero.NewMemMapFs())
+	a.Nil(d.MustFileAt("b.txt").MustEnsureDir(0750).SetContentString("abc"))
+	a.Nil(d.MustFileAt("a/x.txt").MustEnsureDir(0750).SetContentString(""))
+
+	manifest := d.MustFileAt("SHA256SUMS")
+	a.Nil(d.WriteChecksumManifest(manifest, HashSha256))
+	a.Equal("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  a/x.txt\n"+
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  b.txt\n", manifest.MustContentString())
+
+	bsd := DirWithFs("/tmp/other", d.fs).MustEnsure(0750).MustFileAt("release.md5")
+	a.Nil(d.WriteChecksumManifestWithFormat(bsd, HashMd5, ChecksumFormatBSD))
+	a.Equal("MD5 (SHA256SUMS) = "+manifest.MustMd5Hash()+"\n"+
+		"MD5 (a/x.txt) = d41d8cd98f00b204e9800998ecf8427e\n"+
+		"MD5 (b.txt) = 900150983cd24fb0d6963f7d28e17f72\n", bsd.MustContentString())
+}
+
+func TestDir_VerifyChecksumManifest(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs("/tmp/release", afero.NewMemMapFs())
+	for _, name := range []string{"ok.txt", "changed.txt", "missing.txt"} {
+		a.Nil(d.MustFileAt(name).MustEnsureDir(0750).SetContentString(name))
+	}
+	manifest := d.MustFileAt("CHECKSUMS")
+	a.Nil(d.WriteChecksumManifestWithFormat(manifest, HashSha1, ChecksumFormatBSD))
+
+	report, err := d.VerifyChecksumManifest(manifest)
+	a.Nil(err)
+	a.True(report.Valid())
+	a.Len(report.Matched, 3)
+
+	a.Nil(d.MustFileAt("changed.txt").SetContentString("changed"))
+	a.Nil(d.MustFileAt("missing.txt").Remove())
+	a.Nil(d.MustFileAt("new.txt").SetContentString("new"))
+	report, err = d.VerifyChecksumManifest(manifest)
+	a.Nil(err)
+	a.False(report.Valid())
+	a.Equal([]string{"ok.txt"}, report.Matched)
+	a.Equal([]string{"changed.txt"}, report.Mismatched)
+	a.Equal([]string{"missing.txt"}, report.Missing)
+	a.Equal([]string{"new.txt"}, report.Unexpected)
+
+	// GNU format with binary marker and escaped names, algorithm from the digest length
+	a.Nil(d.MustFileAt("back\\slash").SetContentString("abc"))
+	a.Nil(manifest.SetContentString("# comment\n" +
+		"900150983cd24fb0d6963f7d28e17f72 *new.txt\n" +
+		"\\900150983cd24fb0d6963f7d28e17f72  back\\\\slash\n"))
+	report, err = d.VerifyChecksumManifest(manifest)
+	a.Nil(err)
+	a.Equal([]string{"back\\slash"}, report.Matched)
+	a.Equal([]string{"new.txt"}, report.Mismatched)
+
+	a.Nil(manifest.SetContentString("900150983cd24fb0d6963f7d28e17f72  ../outside\n"))
+	_, err = d.VerifyChecksumManifest(manifest)
+	a.NotNil(err)
+	a.Nil(manifest.SetContentString("not a checksum line\n"))
+	_, err = d.VerifyChecksumManifest(manifest)
+	a.NotNil(err)
+	// the mode marker is required
+	a.Nil(manifest.SetContentString("900150983cd24fb0d6963f7d28e17f72 new.txt\n"))
+	_, err = d.VerifyChecksumManifest(manifest)
+	a.True(errors.IsNotValid(err))
+}
+
+func TestAlgorithmForGnuManifest(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	sha256Digest := strings.Repeat("0", 64)
+	a.Equal(HashSha256.Name(), algorithmForGnuManifest(FileWithFs("/web2-SUMS", fs), sha256Digest).Name())
+	a.Equal(HashSha256.Name(), algorithmForGnuManifest(FileWithFs("/db2.txt", fs), sha256Digest).Name())
+	a.Equal(HashBlake3.Name(), algorithmForGnuManifest(FileWithFs("/BLAKE3SUMS", fs), sha256Digest).Name())
+	a.Equal(HashBlake2b512.Name(), algorithmForGnuManifest(FileWithFs("/B2SUMS", fs), strings.Repeat("0", 128)).Name())
+	a.Equal(HashSha1.Name(), algorithmForGnuManifest(FileWithFs("/MD5SUMS", fs), strings.Repeat("0", 40)).Name())
+}
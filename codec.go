@@ -0,0 +1,137 @@
+package gofs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec converts between Go values and the structured content of files.
+type Codec interface {
+	// Name returns a short name of the format, e.g. "json".
+	Name() string
+	// Encode returns the encoded representation of v.
+	Encode(v any) ([]byte, error)
+	// Decode parses content and stores the result in the value pointed to by v.
+	Decode(content []byte, v any) error
+}
+
+// JSONCodec encodes and decodes JSON.
+type JSONCodec struct {
+	// Indent is the number of spaces used to indent nested values, 0 writes compact JSON.
+	Indent int
+}
+
+func (x JSONCodec) Name() string {
+	return "json"
+}
+
+func (x JSONCodec) Encode(v any) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", strings.Repeat(" ", x.Indent))
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (x JSONCodec) Decode(content []byte, v any) error {
+	return json.Unmarshal(content, v)
+}
+
+// YAMLCodec encodes and decodes YAML.
+type YAMLCodec struct {
+	// Indent is the number of spaces used to indent nested values, 0 uses 2.
+	Indent int
+}
+
+func (x YAMLCodec) Name() string {
+	return "yaml"
+}
+
+func (x YAMLCodec) Encode(v any) ([]byte, error) {
+	indent := x.Indent
+	if indent == 0 {
+		indent = 2
+	}
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(indent)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (x YAMLCodec) Decode(content []byte, v any) error {
+	return yaml.Unmarshal(content, v)
+}
+
+// TOMLCodec encodes and decodes TOML. Only structs and maps can be encoded as TOML documents.
+type TOMLCodec struct {
+	// Indent is the number of spaces used to indent nested tables, 0 does not indent them.
+	Indent int
+}
+
+func (x TOMLCodec) Name() string {
+	return "toml"
+}
+
+func (x TOMLCodec) Encode(v any) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := toml.NewEncoder(&b)
+	encoder.Indent = strings.Repeat(" ", x.Indent)
+	err := encoder.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func (x TOMLCodec) Decode(content []byte, v any) error {
+	return toml.Unmarshal(content, v)
+}
+
+var (
+	codecsMutex sync.RWMutex
+	codecs      = map[string]Codec{
+		"json": JSONCodec{Indent: 2},
+		"yaml": YAMLCodec{Indent: 2},
+		"yml":  YAMLCodec{Indent: 2},
+		"toml": TOMLCodec{},
+	}
+)
+
+// RegisterCodec makes codec the one used for files with the given extension, replacing any codec
+// registered for it before. Extensions are case insensitive.
+func RegisterCodec(extension FileExtension, codec Codec) {
+	codecsMutex.Lock()
+	defer codecsMutex.Unlock()
+	codecs[strings.ToLower(extension.WithoutDot())] = codec
+}
+
+// CodecFor returns the codec registered for the given extension. JSON (.json), YAML (.yaml, .yml)
+// and TOML (.toml) are registered by default.
+func CodecFor(extension FileExtension) (Codec, error) {
+	codecsMutex.RLock()
+	defer codecsMutex.RUnlock()
+
+	codec, ok := codecs[strings.ToLower(extension.WithoutDot())]
+	if !ok {
+		return nil, errors.NotFoundf("codec for file extension %s", extension)
+	}
+	return codec, nil
+}
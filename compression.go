@@ -0,0 +1,122 @@
+package gofs
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressionCodec compresses and decompresses streams in one format.
+type compressionCodec struct {
+	name      string
+	newReader func(r io.Reader) (io.ReadCloser, error)
+	// newWriter is nil for formats that can only be read.
+	newWriter func(w io.Writer, level CompressionLevel) (io.WriteCloser, error)
+}
+
+func (x compressionCodec) reader(r io.Reader) (io.ReadCloser, error) {
+	cr, err := x.newReader(r)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not decompress %s", x.name)
+	}
+	return cr, nil
+}
+
+func (x compressionCodec) writer(w io.Writer, level CompressionLevel) (io.WriteCloser, error) {
+	if x.newWriter == nil {
+		return nil, errors.NotSupportedf("writing %s", x.name)
+	}
+	return x.newWriter(w, level)
+}
+
+var (
+	gzipCodec = compressionCodec{
+		name: "gzip",
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		newWriter: func(w io.Writer, level CompressionLevel) (io.WriteCloser, error) {
+			gzipLevel := gzip.DefaultCompression
+			switch level {
+			case CompressionNone:
+				gzipLevel = gzip.NoCompression
+			case CompressionFastest:
+				gzipLevel = gzip.BestSpeed
+			case CompressionBest:
+				gzipLevel = gzip.BestCompression
+			}
+			return gzip.NewWriterLevel(w, gzipLevel)
+		},
+	}
+	zstdCodec = compressionCodec{
+		name: "zstd",
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+		newWriter: func(w io.Writer, level CompressionLevel) (io.WriteCloser, error) {
+			zstdLevel := zstd.SpeedDefault
+			switch level {
+			case CompressionNone, CompressionFastest:
+				zstdLevel = zstd.SpeedFastest
+			case CompressionBest:
+				zstdLevel = zstd.SpeedBestCompression
+			}
+			return zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel))
+		},
+	}
+	xzCodec = compressionCodec{
+		name: "xz",
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			reader, err := xz.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(reader), nil
+		},
+		newWriter: func(w io.Writer, level CompressionLevel) (io.WriteCloser, error) {
+			return xz.NewWriter(w)
+		},
+	}
+	bzip2Codec = compressionCodec{
+		name: "bzip2",
+		newReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		},
+	}
+)
+
+// compressionCodecs maps the extensions of compressed files to their codec.
+var compressionCodecs = []struct {
+	extension FileExtension
+	codec     compressionCodec
+}{
+	{ExtGz, gzipCodec},
+	{ExtTgz, gzipCodec},
+	{ExtZst, zstdCodec},
+	{FileExtensionFrom("tzst"), zstdCodec},
+	{ExtXz, xzCodec},
+	{FileExtensionFrom("txz"), xzCodec},
+	{ExtBz2, bzip2Codec},
+	{FileExtensionFrom("tbz2"), bzip2Codec},
+}
+
+// compressionCodecFor returns the codec for the extension of f, false if it is not compressed.
+// Extensions are matched case insensitively.
+func compressionCodecFor(f File) (compressionCodec, bool) {
+	filename := strings.ToLower(f.Filename())
+	for _, entry := range compressionCodecs {
+		if strings.HasSuffix(filename, entry.extension.WithDot()) {
+			return entry.codec, true
+		}
+	}
+	return compressionCodec{}, false
+}
@@ -0,0 +1,57 @@
+package gofs
+
+import (
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// OverwritePolicy defines what happens when a copy target already exists.
+type OverwritePolicy int
+
+const (
+	// OverwriteAlways replaces existing targets.
+	OverwriteAlways OverwritePolicy = iota
+	// OverwriteNever keeps existing targets and skips copying them.
+	OverwriteNever
+	// OverwriteError aborts with a FileExistsError if a target exists.
+	OverwriteError
+	// OverwriteIfNewer replaces existing targets only if the source was modified more recently.
+	OverwriteIfNewer
+)
+
+// CopyOptions configures recursive and metadata preserving copies.
+type CopyOptions struct {
+	Overwrite OverwritePolicy
+	// PreserveMode copies the permission bits of files and directories.
+	PreserveMode bool
+	// PreserveTimes copies the modification time of files and directories.
+	PreserveTimes bool
+	// PreserveSymlinks recreates symlinks instead of copying what they point to. This requires both
+	// filesystems to support symlinks.
+	PreserveSymlinks bool
+	// DirPermissions is used for created directories unless PreserveMode is set. Defaults to 0750.
+	DirPermissions os.FileMode
+	// Ignore selects ignore rules, ignored files and dirs are not copied.
+	Ignore IgnoreOptions
+}
+
+func (x CopyOptions) dirPermissions(info os.FileInfo) os.FileMode {
+	if x.PreserveMode && info != nil {
+		return info.Mode().Perm()
+	}
+	if x.DirPermissions == 0 {
+		return 0750
+	}
+	return x.DirPermissions
+}
+
+// sameFs returns true if both filesystems are known to be the same backend.
+func sameFs(a, b afero.Fs) bool {
+	if a == b {
+		return true
+	}
+	_, aIsOs := a.(*afero.OsFs)
+	_, bIsOs := b.(*afero.OsFs)
+	return aIsOs && bIsOs
+}
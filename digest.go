@@ -0,0 +1,63 @@
+package gofs
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/juju/errors"
+)
+
+// Digest is the result of hashing content with a HashAlgorithm.
+type Digest struct {
+	algorithm HashAlgorithm
+	sum       []byte
+}
+
+func NewDigest(algorithm HashAlgorithm, sum []byte) Digest {
+	return Digest{
+		algorithm: algorithm,
+		sum:       sum,
+	}
+}
+
+// ParseHexDigest parses a hex encoded digest of the given algorithm.
+func ParseHexDigest(algorithm HashAlgorithm, hexDigest string) (Digest, error) {
+	sum, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return Digest{}, errors.Annotatef(err, "invalid %s digest %s", algorithm, hexDigest)
+	}
+	if len(sum) != algorithm.Size() {
+		return Digest{}, errors.NotValidf("%s digest %s of length %d", algorithm, hexDigest, len(sum))
+	}
+	return NewDigest(algorithm, sum), nil
+}
+
+// Algorithm returns the algorithm used to compute the digest.
+func (x Digest) Algorithm() HashAlgorithm {
+	return x.algorithm
+}
+
+// Bytes returns the raw digest.
+func (x Digest) Bytes() []byte {
+	return x.sum
+}
+
+// Hex returns the digest as lowercase hex string.
+func (x Digest) Hex() string {
+	return hex.EncodeToString(x.sum)
+}
+
+// Base64 returns the digest in standard base64 encoding.
+func (x Digest) Base64() string {
+	return base64.StdEncoding.EncodeToString(x.sum)
+}
+
+// Equals returns true if both digests were computed by the same algorithm and are equal.
+func (x Digest) Equals(other Digest) bool {
+	return x.algorithm.name == other.algorithm.name && bytes.Equal(x.sum, other.sum)
+}
+
+func (x Digest) String() string {
+	return x.Hex()
+}
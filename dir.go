@@ -1,15 +1,12 @@
 package gofs
 
 import (
-	"fmt"
 	"github.com/pkg/browser"
 	"github.com/spf13/afero"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
-
-	"github.com/mitchellh/go-homedir"
 )
 
 // Dir is a filesystem directory.
@@ -27,17 +24,26 @@ func WorkingDir() Dir {
 }
 
 func DirWithFs(path string, fs afero.Fs) Dir {
-	// replace home dir path
-	path, _ = homedir.Expand(path)
+	dir, err := NewDirWithFs(path, fs)
+	if err != nil {
+		// this should not happen
+		panic(err)
+	}
+	return dir
+}
 
-	// make path absolute
-	if !filepath.IsAbs(path) {
-		pwd, err := os.Getwd()
-		if err != nil {
-			// this should not happen
-			panic(err)
-		}
-		path = filepath.Join(pwd, path)
+// NewDir returns the dir at path on the OS filesystem. In contrast to DirAt, an error is returned if
+// the path can not be made absolute.
+func NewDir(path string) (Dir, error) {
+	return NewDirWithFs(path, afero.NewOsFs())
+}
+
+// NewDirWithFs returns the dir at path on fs. In contrast to DirWithFs, an error is returned if the
+// path can not be made absolute.
+func NewDirWithFs(path string, fs afero.Fs) (Dir, error) {
+	path, err := absolutePath(path)
+	if err != nil {
+		return Dir{fs: fs}, err
 	}
 
 	// remove trailing path separator if it exists
@@ -46,7 +52,18 @@ func DirWithFs(path string, fs afero.Fs) Dir {
 	return Dir{
 		path: path,
 		fs:   fs,
-	}
+	}, nil
+}
+
+// WithFs returns this dir on another filesystem, keeping the path.
+func (x Dir) WithFs(fs afero.Fs) Dir {
+	x.fs = fs
+	return x
+}
+
+// Fs returns the filesystem this dir lives on.
+func (x Dir) Fs() afero.Fs {
+	return x.fs
 }
 
 func (x Dir) Create(perm os.FileMode) error {
@@ -67,8 +84,9 @@ func (x Dir) MustEnsure(perm os.FileMode) Dir {
 
 func (x Dir) EnsureEmpty(perm os.FileMode) error {
 	if x.Exists() {
-		if x.IsEmpty() {
-			return nil
+		empty, err := x.IsEmptyE()
+		if err != nil || empty {
+			return err
 		}
 		return x.Clear()
 	}
@@ -76,23 +94,42 @@ func (x Dir) EnsureEmpty(perm os.FileMode) error {
 }
 
 func (x Dir) IsEmpty() bool {
+	empty, err := x.IsEmptyE()
+	if err != nil {
+		panic(err)
+	}
+	return empty
+}
+
+// IsEmptyE returns if this dir has no contents. A dir that does not exist is empty. An error is
+// returned if the dir exists but can not be read.
+func (x Dir) IsEmptyE() (bool, error) {
 	if !x.Exists() {
-		return true
+		return true, nil
 	}
 
 	files, err := afero.ReadDir(x.fs, x.Path())
 	if err != nil {
-		panic(err)
+		return false, err
 	}
 
-	return len(files) == 0
+	return len(files) == 0, nil
 }
 
 func (x Dir) AssertEmpty() Dir {
-	if !x.IsEmpty() {
-		panic(fmt.Errorf("dir %s should have been empty", x))
+	return mustCheck(x, x.CheckEmpty())
+}
+
+// CheckEmpty returns an AssertionError wrapping ErrNotEmpty if this dir has contents.
+func (x Dir) CheckEmpty() error {
+	empty, err := x.IsEmptyE()
+	if err != nil {
+		return err
 	}
-	return x
+	if !empty {
+		return NewAssertionError(ErrNotEmpty, x.path, "dir %s should have been empty", x)
+	}
+	return nil
 }
 
 func (x Dir) IsReadable() bool {
@@ -104,41 +141,95 @@ func (x Dir) IsReadable() bool {
 }
 
 func (x Dir) AssertReadable() Dir {
+	return mustCheck(x, x.CheckReadable())
+}
+
+// CheckReadable returns an AssertionError wrapping ErrNotReadable if this dir can not be read.
+func (x Dir) CheckReadable() error {
 	if !x.IsReadable() {
-		panic(fmt.Errorf("dir %s should have been readable", x))
+		return NewAssertionError(ErrNotReadable, x.path, "dir %s should have been readable", x)
 	}
-	return x
+	return nil
 }
 
 func (x Dir) IsWritable() bool {
+	writable, err := x.IsWritableE()
+	if err != nil {
+		panic(err)
+	}
+	return writable
+}
+
+// IsWritableE returns if files can be created in this dir. A test file is written and removed for
+// the check, an error is returned if the test file already exists or can not be removed.
+func (x Dir) IsWritableE() (bool, error) {
 	if !x.Exists() {
-		return false
+		return false, nil
 	}
 	// Testfile
-	t := x.MustFileAt("__writetest_gofs")
-	t.AssertNotExists()
-	defer t.MustRemove()
-	err := t.SetContent([]byte("a"))
-	return err == nil
+	t, err := x.FileAt("__writetest_gofs")
+	if err != nil {
+		return false, err
+	}
+	err = t.CheckNotExists()
+	if err != nil {
+		return false, err
+	}
+	err = t.SetContent([]byte("a"))
+	if err != nil {
+		return false, nil
+	}
+	return true, t.Remove()
 }
 
 func (x Dir) AssertWritable() Dir {
-	if !x.IsWritable() {
-		panic(fmt.Errorf("dir %s should have been writable", x))
+	return mustCheck(x, x.CheckWritable())
+}
+
+// CheckWritable returns an AssertionError wrapping ErrNotWritable if files can not be created in this
+// dir.
+func (x Dir) CheckWritable() error {
+	writable, err := x.IsWritableE()
+	if err != nil {
+		return err
 	}
-	return x
+	if !writable {
+		return NewAssertionError(ErrNotWritable, x.path, "dir %s should have been writable", x)
+	}
+	return nil
 }
 
 func (x Dir) AssertNotEmpty() Dir {
-	if x.IsEmpty() {
-		panic(fmt.Errorf("dir %s should not have been empty", x))
+	return mustCheck(x, x.CheckNotEmpty())
+}
+
+// CheckNotEmpty returns an AssertionError wrapping ErrEmpty if this dir has no contents.
+func (x Dir) CheckNotEmpty() error {
+	empty, err := x.IsEmptyE()
+	if err != nil {
+		return err
 	}
-	return x
+	if empty {
+		return NewAssertionError(ErrEmpty, x.path, "dir %s should not have been empty", x)
+	}
+	return nil
 }
 
 func (x Dir) Exists() bool {
+	exists, _ := x.ExistsE()
+	return exists
+}
+
+// ExistsE returns if this dir exists. Errors other than the dir not existing are returned.
+func (x Dir) ExistsE() (bool, error) {
 	fi, err := x.fs.Stat(x.Path())
-	return !os.IsNotExist(err) && fi.IsDir()
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return fi.IsDir(), nil
 }
 
 func (x Dir) ReadDir() ([]os.FileInfo, error) {
@@ -161,11 +252,23 @@ func (x Dir) Files() ([]File, error) {
 	return files, nil
 }
 
+// FilesWithOptions returns the files directly in this dir like Files, honoring the ignore rules of
+// opts. opts.MaxDepth is ignored.
+func (x Dir) FilesWithOptions(opts WalkOptions) ([]File, error) {
+	opts.MaxDepth = 1
+	return x.AllFiles(opts)
+}
+
 func (x Dir) AssertExists() Dir {
+	return mustCheck(x, x.CheckExists())
+}
+
+// CheckExists returns an AssertionError wrapping ErrNotExist if this dir does not exist.
+func (x Dir) CheckExists() error {
 	if !x.Exists() {
-		panic(fmt.Errorf("dir %s should have existed", x))
+		return NewAssertionError(ErrNotExist, x.path, "dir %s should have existed", x)
 	}
-	return x
+	return nil
 }
 
 func (x Dir) NotExists() bool {
@@ -173,10 +276,15 @@ func (x Dir) NotExists() bool {
 }
 
 func (x Dir) AssertNotExists() Dir {
+	return mustCheck(x, x.CheckNotExists())
+}
+
+// CheckNotExists returns an AssertionError wrapping ErrExist if this dir exists.
+func (x Dir) CheckNotExists() error {
 	if !x.NotExists() {
-		panic(fmt.Errorf("dir %s should not have existed", x))
+		return NewAssertionError(ErrExist, x.path, "dir %s should not have existed", x)
 	}
-	return x
+	return nil
 }
 
 func (x Dir) Path() string {
@@ -241,7 +349,7 @@ func (x Dir) Remove() error {
 
 func (x Dir) Clear() error {
 	dir := x.Path()
-	d, err := os.Open(dir)
+	d, err := x.fs.Open(dir)
 	if err != nil {
 		return err
 	}
@@ -251,7 +359,7 @@ func (x Dir) Clear() error {
 		return err
 	}
 	for _, name := range names {
-		err = os.RemoveAll(filepath.Join(dir, name))
+		err = x.fs.RemoveAll(filepath.Join(dir, name))
 		if err != nil {
 			return err
 		}
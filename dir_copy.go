@@ -0,0 +1,128 @@
+package gofs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// CopyTo copies this dir recursively so that target has the same contents afterwards. target is
+// created if necessary. Existing files in target are handled according to opts.Overwrite, files in
+// target that do not exist in this dir are left untouched.
+func (x Dir) CopyTo(target Dir, opts CopyOptions) error {
+	return x.copyTo(target, opts, make(map[string]bool))
+}
+
+// copyTo copies this dir like CopyTo. visited contains the real paths of the dirs currently being
+// copied, so that following a symlink to one of them is detected as a cycle.
+func (x Dir) copyTo(target Dir, opts CopyOptions, visited map[string]bool) error {
+	info, err := x.fs.Stat(x.Path())
+	if err != nil {
+		return errors.Annotatef(err, "Error reading source dir")
+	}
+	if !info.IsDir() {
+		return errors.NotValidf("source %s is not a dir", x)
+	}
+	if sameFs(x.fs, target.fs) && isPathBelow(target.Path(), x.Path()) {
+		return errors.NotValidf("copying %s into itself (%s)", x, target)
+	}
+
+	realPath, err := realDirPath(x.fs, x.Path())
+	if err != nil {
+		return errors.Annotatef(err, "Error resolving source dir")
+	}
+	if visited[realPath] {
+		return errors.NotValidf("copying %s: symlink cycle", x)
+	}
+	visited[realPath] = true
+	defer delete(visited, realPath)
+
+	type copiedDir struct {
+		path string
+		info os.FileInfo
+	}
+	copiedDirs := []copiedDir{{target.Path(), info}}
+
+	err = target.fs.MkdirAll(target.Path(), opts.dirPermissions(info))
+	if err != nil {
+		return errors.Annotatef(err, "Error creating target dir")
+	}
+
+	err = x.Walk(WalkOptions{Ignore: opts.Ignore}, func(entry WalkEntry) error {
+		targetPath := filepath.Join(target.Path(), entry.RelativePath())
+
+		if entry.Info().Mode()&os.ModeSymlink != 0 {
+			if opts.PreserveSymlinks {
+				return copySymlink(x.fs, entry.Path(), target.fs, targetPath, opts)
+			}
+			// follow the symlink
+			linkedInfo, err := x.fs.Stat(entry.Path())
+			if err != nil {
+				return errors.Annotatef(err, "Error following symlink %s", entry.Path())
+			}
+			if linkedInfo.IsDir() {
+				return entry.Dir().copyTo(DirWithFs(targetPath, target.fs), opts, visited)
+			}
+			return entry.File().copyWithOptions(FileWithFs(targetPath, target.fs), opts)
+		}
+
+		if entry.IsDir() {
+			copiedDirs = append(copiedDirs, copiedDir{targetPath, entry.Info()})
+			return target.fs.MkdirAll(targetPath, opts.dirPermissions(entry.Info()))
+		}
+
+		return entry.File().copyWithOptions(FileWithFs(targetPath, target.fs), opts)
+	})
+	if err != nil {
+		return err
+	}
+
+	// apply dir metadata last, deepest first, because copying the contents modifies the dirs
+	for i := len(copiedDirs) - 1; i >= 0; i-- {
+		err = applyMetadata(target.fs, copiedDirs[i].path, copiedDirs[i].info, opts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x Dir) MustCopyTo(target Dir, opts CopyOptions) Dir {
+	err := x.CopyTo(target, opts)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// realDirPath returns the path of the dir at p with all symlinks resolved. On filesystems other than
+// the OS filesystem only a symlink at p itself is resolved.
+func realDirPath(fs afero.Fs, p string) (string, error) {
+	if _, isOs := fs.(*afero.OsFs); isOs {
+		return filepath.EvalSymlinks(p)
+	}
+	info, err := lstat(fs, p)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return filepath.Clean(p), err
+	}
+	linkTarget, err := readSymlink(fs, p)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(linkTarget) {
+		return filepath.Clean(linkTarget), nil
+	}
+	return filepath.Join(filepath.Dir(p), linkTarget), nil
+}
+
+// isPathBelow returns true if p is dir itself or inside of it.
+func isPathBelow(p, dir string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,99 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDir_CopyTo(t *testing.T) {
+	a := assert.New(t)
+	src := newWalkTestDir(t)
+	target := DirWithFs("/tmp/walk-copy", src.fs)
+
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.Nil(src.fs.Chmod(src.MustFileAt("a.txt").Path(), 0600))
+	a.Nil(src.fs.Chtimes(src.MustFileAt("a.txt").Path(), modTime, modTime))
+
+	err := src.CopyTo(target, CopyOptions{PreserveMode: true, PreserveTimes: true})
+	a.Nil(err)
+
+	files, err := target.AllFiles(WalkOptions{})
+	a.Nil(err)
+	a.Len(files, 5)
+	a.Equal("b/c/deep.txt", target.MustFileAt("b/c/deep.txt").MustContentString())
+
+	info, err := target.fs.Stat(target.MustFileAt("a.txt").Path())
+	a.Nil(err)
+	a.Equal(os.FileMode(0600), info.Mode().Perm())
+	a.True(modTime.Equal(info.ModTime()))
+
+	// copying into itself is rejected
+	a.NotNil(src.CopyTo(src.MustDirAt("b/inner"), CopyOptions{}))
+}
+
+func TestDir_CopyTo_Overwrite(t *testing.T) {
+	a := assert.New(t)
+	src := newWalkTestDir(t)
+	target := DirWithFs("/tmp/walk-copy", src.fs)
+	existing := target.MustFileAt("a.txt").MustEnsureDir(0750)
+	a.Nil(existing.SetContentString("existing"))
+
+	err := src.CopyTo(target, CopyOptions{Overwrite: OverwriteError})
+	var existsErr *FileExistsError
+	a.ErrorAs(err, &existsErr)
+
+	a.Nil(src.CopyTo(target, CopyOptions{Overwrite: OverwriteNever}))
+	a.Equal("existing", existing.MustContentString())
+	a.Equal("d/e.txt", target.MustFileAt("d/e.txt").MustContentString())
+
+	old := time.Now().Add(-time.Hour)
+	a.Nil(src.fs.Chtimes(src.MustFileAt("a.txt").Path(), old, old))
+	a.Nil(src.CopyTo(target, CopyOptions{Overwrite: OverwriteIfNewer}))
+	a.Equal("existing", existing.MustContentString())
+
+	a.Nil(src.CopyTo(target, CopyOptions{}))
+	a.Equal("a.txt", existing.MustContentString())
+}
+
+func TestDir_CopyTo_Symlinks(t *testing.T) {
+	a := assert.New(t)
+	src := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.MustFileAt("real.txt").SetContentString("real"))
+	a.Nil(os.Symlink("real.txt", filepath.Join(src.Path(), "link.txt")))
+
+	preserved := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.CopyTo(preserved, CopyOptions{PreserveSymlinks: true}))
+	link, err := os.Readlink(preserved.MustFileAt("link.txt").Path())
+	a.Nil(err)
+	a.Equal("real.txt", link)
+
+	followed := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.CopyTo(followed, CopyOptions{}))
+	info, err := os.Lstat(followed.MustFileAt("link.txt").Path())
+	a.Nil(err)
+	a.Zero(info.Mode() & os.ModeSymlink)
+	a.Equal("real", followed.MustFileAt("link.txt").MustContentString())
+}
+
+func TestDir_CopyTo_SymlinkCycle(t *testing.T) {
+	a := assert.New(t)
+	src := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(os.Mkdir(filepath.Join(src.Path(), "a"), 0750))
+	a.Nil(src.MustFileAt("a/real.txt").SetContentString("real"))
+	a.Nil(os.Symlink("..", filepath.Join(src.Path(), "a", "loop")))
+
+	err := src.CopyTo(DirWithFs(t.TempDir(), afero.NewOsFs()), CopyOptions{})
+	a.True(errors.IsNotValid(err))
+
+	// a dir linked twice without a cycle is copied twice
+	a.Nil(os.Remove(filepath.Join(src.Path(), "a", "loop")))
+	a.Nil(os.Symlink("a", filepath.Join(src.Path(), "b")))
+	target := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.CopyTo(target, CopyOptions{}))
+	a.Equal("real", target.MustFileAt("b/real.txt").MustContentString())
+}
@@ -0,0 +1,160 @@
+package gofs
+
+import (
+	"io"
+	"sort"
+
+	"github.com/juju/errors"
+)
+
+// partialHashBlockSize is the number of bytes read from the start and the end of files to rule out
+// most duplicate candidates before hashing them completely.
+const partialHashBlockSize = 4096
+
+// DuplicateOptions configures the search for duplicate files.
+type DuplicateOptions struct {
+	// Algorithm is used to compare file contents. Defaults to HashSha256.
+	Algorithm HashAlgorithm
+	// IncludeHidden includes hidden files and the contents of hidden dirs, see File.IsHidden.
+	IncludeHidden bool
+	// MinSize excludes files smaller than this many bytes. Empty files are never reported.
+	MinSize int64
+	// HardlinkAware treats hardlinks to the same data as a single file, so they are not reported as
+	// duplicates of each other. Only effective on filesystems exposing inodes.
+	HardlinkAware bool
+	// Ignore selects ignore rules, ignored files are not considered.
+	Ignore IgnoreOptions
+}
+
+func (x DuplicateOptions) algorithm() HashAlgorithm {
+	if x.Algorithm.factory == nil {
+		return HashSha256
+	}
+	return x.Algorithm
+}
+
+// DuplicateGroup is a set of files with identical content.
+type DuplicateGroup struct {
+	// Size is the size of each of the files in bytes.
+	Size int64
+	// Files are the identical files sorted by path.
+	Files []File
+}
+
+// FindDuplicates returns groups of files below this dir with identical content. Candidates are
+// narrowed down by size first, then by a hash of their first and last blocks and finally by a hash of
+// their full content. Groups are sorted by the path of their first file.
+func (x Dir) FindDuplicates(opts DuplicateOptions) ([]DuplicateGroup, error) {
+	bySize := make(map[int64][]File)
+	seen := make(map[fileID]bool)
+	err := x.Walk(WalkOptions{Ignore: opts.Ignore}, func(entry WalkEntry) error {
+		if !opts.IncludeHidden && FileWithFs(entry.Path(), x.fs).IsHidden() {
+			if entry.IsDir() {
+				return SkipDir
+			}
+			return nil
+		}
+		info := entry.Info()
+		if !info.Mode().IsRegular() || info.Size() == 0 || info.Size() < opts.MinSize {
+			return nil
+		}
+		if opts.HardlinkAware {
+			if id, ok := fileIDFromInfo(info); ok {
+				if seen[id] {
+					return nil
+				}
+				seen[id] = true
+			}
+		}
+		bySize[info.Size()] = append(bySize[info.Size()], entry.File())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := opts.algorithm()
+	result := make([]DuplicateGroup, 0)
+	for size, candidates := range bySize {
+		if len(candidates) < 2 {
+			continue
+		}
+		byPartialHash, err := groupByDigest(candidates, func(f File) (Digest, error) {
+			return f.partialHash(algorithm, size)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, partialCandidates := range byPartialHash {
+			// small files were read completely already
+			if size <= 2*partialHashBlockSize {
+				result = append(result, newDuplicateGroup(size, partialCandidates))
+				continue
+			}
+			byHash, err := groupByDigest(partialCandidates, func(f File) (Digest, error) {
+				return f.Hash(algorithm)
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, files := range byHash {
+				result = append(result, newDuplicateGroup(size, files))
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Files[0].Path() < result[j].Files[0].Path()
+	})
+	return result, nil
+}
+
+func newDuplicateGroup(size int64, files []File) DuplicateGroup {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path() < files[j].Path()
+	})
+	return DuplicateGroup{Size: size, Files: files}
+}
+
+// groupByDigest groups files by the digest computed by hashFunc, dropping groups of single files.
+func groupByDigest(files []File, hashFunc func(f File) (Digest, error)) ([][]File, error) {
+	groups := make(map[string][]File)
+	for _, f := range files {
+		digest, err := hashFunc(f)
+		if err != nil {
+			return nil, err
+		}
+		groups[string(digest.Bytes())] = append(groups[string(digest.Bytes())], f)
+	}
+
+	result := make([][]File, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// partialHash hashes the first and the last block of this file of the given size.
+func (x File) partialHash(algorithm HashAlgorithm, size int64) (Digest, error) {
+	f, err := x.fs.Open(x.Path())
+	if err != nil {
+		return Digest{}, err
+	}
+	defer f.Close()
+
+	h := algorithm.New()
+	if size <= 2*partialHashBlockSize {
+		_, err = io.Copy(h, f)
+	} else {
+		_, err = io.Copy(h, io.NewSectionReader(f, 0, partialHashBlockSize))
+		if err == nil {
+			_, err = io.Copy(h, io.NewSectionReader(f, size-partialHashBlockSize, partialHashBlockSize))
+		}
+	}
+	if err != nil {
+		return Digest{}, errors.Annotatef(err, "could not read %s", x)
+	}
+	return NewDigest(algorithm, h.Sum(nil)), nil
+}
@@ -0,0 +1,63 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDir_FindDuplicates(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs("/tmp/photos", afero.NewMemMapFs())
+
+	large := strings.Repeat("x", 3*partialHashBlockSize)
+	// same start and end as large, different middle
+	largeVariant := large[:partialHashBlockSize] + strings.Repeat("y", partialHashBlockSize) + large[2*partialHashBlockSize:]
+	contents := map[string]string{
+		"a.jpg":           "photo",
+		"import/a.jpg":    "photo",
+		"import/b.jpg":    "other",
+		"c.png":           large,
+		"import/c.png":    large,
+		"import/d.png":    largeVariant,
+		".thumbs/a.jpg":   "photo",
+		"empty1":          "",
+		"empty2":          "",
+		"import/tiny.txt": "p",
+		"tiny.txt":        "p",
+	}
+	for name, content := range contents {
+		a.Nil(d.MustFileAt(name).MustEnsureDir(0750).SetContentString(content))
+	}
+
+	groups, err := d.FindDuplicates(DuplicateOptions{})
+	a.Nil(err)
+	a.Len(groups, 3)
+	a.Equal(int64(len("photo")), groups[0].Size)
+	a.Equal([]string{"a.jpg", "import/a.jpg"}, relativePaths(d, groups[0].Files))
+	a.Equal([]string{"c.png", "import/c.png"}, relativePaths(d, groups[1].Files))
+	a.Equal([]string{"import/tiny.txt", "tiny.txt"}, relativePaths(d, groups[2].Files))
+
+	groups, err = d.FindDuplicates(DuplicateOptions{IncludeHidden: true, MinSize: 2})
+	a.Nil(err)
+	a.Len(groups, 2)
+	a.Equal([]string{".thumbs/a.jpg", "a.jpg", "import/a.jpg"}, relativePaths(d, groups[0].Files))
+}
+
+func TestDir_FindDuplicates_Hardlinks(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(d.MustFileAt("a.jpg").SetContentString("photo"))
+	a.Nil(os.Link(d.MustFileAt("a.jpg").Path(), filepath.Join(d.Path(), "b.jpg")))
+
+	groups, err := d.FindDuplicates(DuplicateOptions{})
+	a.Nil(err)
+	a.Len(groups, 1)
+
+	groups, err = d.FindDuplicates(DuplicateOptions{HardlinkAware: true})
+	a.Nil(err)
+	a.Len(groups, 0)
+}
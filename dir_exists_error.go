@@ -0,0 +1,21 @@
+package gofs
+
+import "fmt"
+
+type DirExistsError struct {
+	path string
+}
+
+func NewDirExistsError(path string) *DirExistsError {
+	return &DirExistsError{
+		path: path,
+	}
+}
+
+func (x DirExistsError) Error() string {
+	return fmt.Sprintf("dir was expected not to exist, but it did: %s", x.path)
+}
+
+func (x DirExistsError) Unwrap() error {
+	return ErrExist
+}
@@ -0,0 +1,77 @@
+package gofs
+
+import (
+	"path/filepath"
+)
+
+// Recursion defines if an operation on a Dir includes its subdirectories.
+type Recursion bool
+
+const (
+	// Recursive includes all subdirectories.
+	Recursive Recursion = true
+	// NonRecursive only includes the direct contents of a dir.
+	NonRecursive Recursion = false
+)
+
+// Glob returns the files and dirs below this dir whose relative paths match the given patterns.
+// Patterns are slash separated and use the syntax of path.Match for single path elements. In
+// addition, "**" matches any number of path elements, "{a,b}" expands to alternatives and patterns
+// prefixed with "!" exclude what they match. Results are in walk order.
+func (x Dir) Glob(patterns ...string) ([]File, []Dir, error) {
+	matcher, err := compileGlob(patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make([]File, 0)
+	dirs := make([]Dir, 0)
+	err = x.Walk(WalkOptions{MaxDepth: matcher.maxDepth}, func(entry WalkEntry) error {
+		if !matcher.Match(filepath.ToSlash(entry.RelativePath())) {
+			return nil
+		}
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Dir())
+			return nil
+		}
+		files = append(files, entry.File())
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, dirs, nil
+}
+
+// GlobFiles returns the files below this dir matching the given patterns, see Glob.
+func (x Dir) GlobFiles(patterns ...string) ([]File, error) {
+	files, _, err := x.Glob(patterns...)
+	return files, err
+}
+
+// GlobDirs returns the dirs below this dir matching the given patterns, see Glob.
+func (x Dir) GlobDirs(patterns ...string) ([]Dir, error) {
+	_, dirs, err := x.Glob(patterns...)
+	return dirs, err
+}
+
+// FilesWithExtension returns the files in this dir having the given extension, including the
+// ones in subdirectories if recursion is Recursive.
+func (x Dir) FilesWithExtension(fileExtension FileExtension, recursion Recursion) ([]File, error) {
+	opts := WalkOptions{MaxDepth: 1}
+	if recursion {
+		opts.MaxDepth = 0
+	}
+
+	files := make([]File, 0)
+	err := x.WalkFiles(opts, func(file File) error {
+		if file.HasExtension(fileExtension) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
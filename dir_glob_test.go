@@ -0,0 +1,66 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newGlobTestDir(t *testing.T) Dir {
+	d := DirWithFs("/tmp/glob", afero.NewMemMapFs())
+	for _, name := range []string{"a.pdf", "b.jpg", "docs/c.pdf", "docs/old/d.pdf", "img/e.png", "img/f.jpg"} {
+		assert.Nil(t, d.MustFileAt(name).MustEnsureDir(0750).SetContentString(name))
+	}
+	return d
+}
+
+func relativePaths(d Dir, files []File) []string {
+	result := make([]string, 0, len(files))
+	for _, f := range files {
+		result = append(result, f.RelativeTo(d))
+	}
+	return result
+}
+
+func TestDir_Glob(t *testing.T) {
+	a := assert.New(t)
+	d := newGlobTestDir(t)
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"*.pdf"}, []string{"a.pdf"}},
+		{[]string{"**/*.pdf"}, []string{"a.pdf", "docs/c.pdf", "docs/old/d.pdf"}},
+		{[]string{"docs/**"}, []string{"docs/c.pdf", "docs/old/d.pdf"}},
+		{[]string{"**/*.{jpg,png}"}, []string{"b.jpg", "img/e.png", "img/f.jpg"}},
+		{[]string{"**/*.pdf", "!docs/old/**"}, []string{"a.pdf", "docs/c.pdf"}},
+		{[]string{"img/?.png"}, []string{"img/e.png"}},
+		{[]string{"{docs,img}/*"}, []string{"docs/c.pdf", "img/e.png", "img/f.jpg"}},
+	}
+	for _, tt := range tests {
+		files, err := d.GlobFiles(tt.patterns...)
+		a.Nil(err)
+		a.Equal(tt.want, relativePaths(d, files), "%v", tt.patterns)
+	}
+
+	_, dirs, err := d.Glob("**", "!img")
+	a.Nil(err)
+	a.Len(dirs, 2)
+
+	_, err = d.GlobFiles("[")
+	a.NotNil(err)
+}
+
+func TestDir_FilesWithExtension(t *testing.T) {
+	a := assert.New(t)
+	d := newGlobTestDir(t)
+
+	files, err := d.FilesWithExtension(ExtPdf, Recursive)
+	a.Nil(err)
+	a.Equal([]string{"a.pdf", "docs/c.pdf", "docs/old/d.pdf"}, relativePaths(d, files))
+
+	files, err = d.FilesWithExtension(ExtJpg, NonRecursive)
+	a.Nil(err)
+	a.Equal([]string{"b.jpg"}, relativePaths(d, files))
+}
@@ -0,0 +1,170 @@
+package gofs
+
+import (
+	"encoding/binary"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// DirHashOptions configures the computation of a Dir's content hash.
+type DirHashOptions struct {
+	// Algorithm is used for files and dirs. Defaults to HashSha256.
+	Algorithm HashAlgorithm
+	// IncludeHidden includes hidden files and dirs, see File.IsHidden.
+	IncludeHidden bool
+	// IncludeMode makes the permission bits part of the hash.
+	IncludeMode bool
+	// Ignore selects ignore rules, ignored files and dirs are not part of the hash.
+	Ignore IgnoreOptions
+}
+
+func (x DirHashOptions) algorithm() HashAlgorithm {
+	if x.Algorithm.factory == nil {
+		return HashSha256
+	}
+	return x.Algorithm
+}
+
+// DirDigest is a Merkle-style digest of a dir. The digest of every dir is computed from the names,
+// types, optionally modes and digests of its direct contents, so equal digests mean equal trees.
+type DirDigest struct {
+	// Digest is the digest of the hashed dir itself.
+	Digest Digest
+	// Files maps slash separated paths relative to the hashed dir to the digests of file contents.
+	Files map[string]Digest
+	// Dirs maps slash separated paths relative to the hashed dir to the digests of subdirs. The hashed
+	// dir itself is included as ".".
+	Dirs map[string]Digest
+}
+
+// Changed returns the relative paths of all files and dirs whose digest differs from the one in
+// other or that exist in only one of both, sorted. A changed file makes all of its parent dirs
+// change as well, so the deepest dirs in the result pinpoint the changed subtrees.
+func (x *DirDigest) Changed(other *DirDigest) []string {
+	changed := make([]string, 0)
+	compare := func(a, b map[string]Digest) {
+		for p, digest := range a {
+			otherDigest, ok := b[p]
+			if !ok || !digest.Equals(otherDigest) {
+				changed = append(changed, p)
+			}
+		}
+		for p := range b {
+			if _, ok := a[p]; !ok {
+				changed = append(changed, p)
+			}
+		}
+	}
+	compare(x.Files, other.Files)
+	compare(x.Dirs, other.Dirs)
+	sort.Strings(changed)
+	return changed
+}
+
+// IsHidden returns true if the name of this dir starts with a dot.
+func (x Dir) IsHidden() bool {
+	return FileWithFs(x.Path(), x.fs).IsHidden()
+}
+
+// Hash computes a deterministic digest over the relative paths and contents of all files below this
+// dir. The digests of all files and subdirs are returned as well.
+func (x Dir) Hash(opts DirHashOptions) (*DirDigest, error) {
+	result := &DirDigest{
+		Files: make(map[string]Digest),
+		Dirs:  make(map[string]Digest),
+	}
+	digest, err := x.hash(x, opts, newWalkIgnore(x, opts.Ignore), result)
+	if err != nil {
+		return nil, err
+	}
+	result.Digest = digest
+	return result, nil
+}
+
+func (x Dir) MustHash(opts DirHashOptions) *DirDigest {
+	digest, err := x.Hash(opts)
+	if err != nil {
+		panic(err)
+	}
+	return digest
+}
+
+func (x Dir) hash(root Dir, opts DirHashOptions, ignore *walkIgnore, result *DirDigest) (Digest, error) {
+	algorithm := opts.algorithm()
+	rel := filepath.ToSlash(x.RelativeTo(root))
+
+	contents, err := afero.ReadDir(x.fs, x.Path())
+	if err != nil {
+		return Digest{}, errors.Annotatef(err, "could not read dir %s", x)
+	}
+	err = ignore.enter(rel)
+	if err != nil {
+		return Digest{}, err
+	}
+	sort.Slice(contents, func(i, j int) bool {
+		return contents[i].Name() < contents[j].Name()
+	})
+
+	h := algorithm.New()
+	for _, content := range contents {
+		contentPath := filepath.Join(x.Path(), content.Name())
+		contentRel := path.Join(rel, content.Name())
+		if !opts.IncludeHidden && FileWithFs(contentPath, x.fs).IsHidden() {
+			continue
+		}
+		if ignore.ignored(contentRel, content.IsDir()) {
+			continue
+		}
+
+		var kind byte
+		var digest Digest
+		switch {
+		case content.Mode()&os.ModeSymlink != 0:
+			kind = 'l'
+			digest, err = hashSymlink(x.fs, contentPath, algorithm)
+		case content.IsDir():
+			kind = 'd'
+			digest, err = DirWithFs(contentPath, x.fs).hash(root, opts, ignore, result)
+		default:
+			kind = 'f'
+			digest, err = FileWithFs(contentPath, x.fs).Hash(algorithm)
+			result.Files[contentRel] = digest
+		}
+		if err != nil {
+			return Digest{}, err
+		}
+
+		h.Write([]byte{kind})
+		h.Write(binary.AppendUvarint(nil, uint64(len(content.Name()))))
+		h.Write([]byte(content.Name()))
+		if opts.IncludeMode {
+			h.Write(binary.BigEndian.AppendUint32(nil, uint32(content.Mode().Perm())))
+		}
+		h.Write(digest.Bytes())
+	}
+
+	digest := NewDigest(algorithm, h.Sum(nil))
+	result.Dirs[rel] = digest
+	return digest, nil
+}
+
+// hashSymlink hashes the target of a symlink, or the content it points to if the filesystem can not
+// read symlinks.
+func hashSymlink(fs afero.Fs, linkPath string, algorithm HashAlgorithm) (Digest, error) {
+	reader, ok := fs.(afero.LinkReader)
+	if !ok {
+		return FileWithFs(linkPath, fs).Hash(algorithm)
+	}
+	target, err := reader.ReadlinkIfPossible(linkPath)
+	if err != nil {
+		return Digest{}, errors.Annotatef(err, "could not read symlink %s", linkPath)
+	}
+	h := algorithm.New()
+	h.Write([]byte(target))
+	return NewDigest(algorithm, h.Sum(nil)), nil
+}
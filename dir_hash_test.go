@@ -0,0 +1,44 @@
+package gofs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDir_Hash(t *testing.T) {
+	a := assert.New(t)
+	d := newWalkTestDir(t)
+
+	first, err := d.Hash(DirHashOptions{})
+	a.Nil(err)
+	a.Len(first.Files, 4)
+	a.Len(first.Dirs, 4)
+	a.Equal(HashSha256.Name(), first.Digest.Algorithm().Name())
+	a.True(first.Digest.Equals(first.Dirs["."]))
+
+	// a copy has the same digest
+	copied := DirWithFs("/tmp/walk-copy", d.fs)
+	a.Nil(d.CopyTo(copied, CopyOptions{}))
+	a.True(first.Digest.Equals(copied.MustHash(DirHashOptions{}).Digest))
+
+	// changes pinpoint the subtree
+	a.Nil(copied.MustFileAt("b/c/deep.txt").SetContentString("changed"))
+	second := copied.MustHash(DirHashOptions{})
+	a.False(first.Digest.Equals(second.Digest))
+	a.Equal([]string{".", "b", "b/c", "b/c/deep.txt"}, first.Changed(second))
+
+	// hidden files only count if requested
+	a.Nil(d.MustFileAt(".hidden/x").SetContentString("changed"))
+	a.True(first.Digest.Equals(d.MustHash(DirHashOptions{}).Digest))
+	withHidden := d.MustHash(DirHashOptions{IncludeHidden: true})
+	a.Len(withHidden.Files, 5)
+
+	a.Equal(HashBlake3.Name(), d.MustHash(DirHashOptions{Algorithm: HashBlake3}).Digest.Algorithm().Name())
+
+	// modes are only considered if requested
+	a.Nil(d.fs.Chmod(d.MustFileAt("a.txt").Path(), 0600))
+	a.True(first.Digest.Equals(d.MustHash(DirHashOptions{}).Digest))
+	a.Nil(d.fs.Chmod(copied.MustFileAt("a.txt").Path(), 0644))
+	a.Nil(copied.MustFileAt("b/c/deep.txt").SetContentString("b/c/deep.txt"))
+	a.False(d.MustHash(DirHashOptions{IncludeMode: true}).Digest.Equals(copied.MustHash(DirHashOptions{IncludeMode: true}).Digest))
+}
@@ -0,0 +1,71 @@
+package gofs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+)
+
+// MoveTo moves this dir with all of its contents to target. A rename is used if both dirs are on the
+// same filesystem. If they are not or the rename fails because the target is on another device, the
+// contents are copied, verified and the source is removed. An existing empty target dir is
+// replaced, a DirExistsError is returned if target exists and is not empty.
+func (x Dir) MoveTo(target Dir) error {
+	return x.MoveToWithOptions(target, MoveOptions{})
+}
+
+// MoveToWithOptions moves this dir to target like MoveTo.
+func (x Dir) MoveToWithOptions(target Dir, opts MoveOptions) error {
+	if opts.NoOverwrite && target.Exists() {
+		return NewDirExistsError(target.Path())
+	}
+	// renaming fails for non-empty targets, so copying must not merge into them either
+	empty, err := target.IsEmptyE()
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return NewDirExistsError(target.Path())
+	}
+	if target.Exists() {
+		err = target.fs.Remove(target.Path())
+		if err != nil {
+			return errors.Annotatef(err, "could not replace %s", target)
+		}
+	}
+
+	if sameFs(x.fs, target.fs) {
+		err := x.fs.Rename(x.Path(), target.Path())
+		if err == nil || !needsCopyFallback(err) {
+			return err
+		}
+	}
+
+	err = x.CopyTo(target, moveCopyOptions(x.fs, target.fs))
+	if err != nil {
+		return errors.Annotatef(err, "could not copy %s to %s", x, target)
+	}
+	err = x.WalkFiles(WalkOptions{}, func(file File) error {
+		info, err := lstat(x.fs, file.Path())
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return verifyCopy(file, FileWithFs(filepath.Join(target.Path(), file.RelativeTo(x)), target.fs))
+	})
+	if err != nil {
+		return err
+	}
+	return x.fs.RemoveAll(x.Path())
+}
+
+func (x Dir) MustMoveTo(target Dir) Dir {
+	err := x.MoveTo(target)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
@@ -15,3 +15,7 @@ func NewDirPathNotRelativeError(path string) *DirPathNotRelativeError {
 func (x DirPathNotRelativeError) Error() string {
 	return fmt.Sprintf("dir path was expected to be relative, but it was not: %s", x.path)
 }
+
+func (x DirPathNotRelativeError) Unwrap() error {
+	return ErrPathNotRelative
+}
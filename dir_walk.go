@@ -0,0 +1,267 @@
+package gofs
+
+import (
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	// SkipDir can be returned from a WalkFunc to skip the contents of the current directory.
+	SkipDir = fs.SkipDir
+	// SkipAll can be returned from a WalkFunc to stop walking altogether.
+	SkipAll = fs.SkipAll
+)
+
+// WalkOptions configures a recursive walk of a Dir.
+type WalkOptions struct {
+	// MaxDepth limits how deep the walk descends. 1 means only the direct children of the walked
+	// dir, 0 means no limit.
+	MaxDepth int
+	// Ignore selects ignore rules, ignored files and dirs are neither visited nor descended into.
+	Ignore IgnoreOptions
+}
+
+// WalkFunc is called for every entry found while walking a Dir.
+type WalkFunc func(entry WalkEntry) error
+
+// WalkEntry is a single file or directory found while walking a Dir.
+type WalkEntry struct {
+	root  Dir
+	path  string
+	info  os.FileInfo
+	depth int
+	skip  *bool
+}
+
+// IsDir returns true if the entry is a directory.
+func (x WalkEntry) IsDir() bool {
+	return x.info != nil && x.info.IsDir()
+}
+
+// File returns the entry as a File, using the filesystem of the walked Dir.
+func (x WalkEntry) File() File {
+	return FileWithFs(x.path, x.root.fs)
+}
+
+// Dir returns the entry as a Dir, using the filesystem of the walked Dir.
+func (x WalkEntry) Dir() Dir {
+	return DirWithFs(x.path, x.root.fs)
+}
+
+// Info returns the os.FileInfo of the entry as returned when reading its parent directory. It is
+// nil for entries yielded with an error if the entry could not be read at all.
+func (x WalkEntry) Info() os.FileInfo {
+	return x.info
+}
+
+// Name returns the last path element of the entry.
+func (x WalkEntry) Name() string {
+	if x.info == nil {
+		if x.path == "" {
+			return ""
+		}
+		return filepath.Base(x.path)
+	}
+	return x.info.Name()
+}
+
+// Path returns the absolute path of the entry.
+func (x WalkEntry) Path() string {
+	return x.path
+}
+
+// RelativePath returns the path of the entry relative to the walked Dir.
+func (x WalkEntry) RelativePath() string {
+	if x.path == "" {
+		return ""
+	}
+	result, err := filepath.Rel(x.root.Path(), x.path)
+	if err != nil {
+		return x.path
+	}
+	return result
+}
+
+// Depth returns the depth of the entry, 1 being a direct child of the walked Dir.
+func (x WalkEntry) Depth() int {
+	return x.depth
+}
+
+// SkipDir makes the walk not descend into this directory. It is meant to be used with the iterator
+// forms of walking where returning SkipDir is not possible.
+func (x WalkEntry) SkipDir() {
+	if x.skip != nil {
+		*x.skip = true
+	}
+}
+
+// Walk calls fn for every file and directory below this dir. Entries are visited depth-first in
+// lexical order, a directory is visited before its contents. The dir itself is not visited.
+// Symlinks are reported but never followed.
+//
+// If fn returns SkipDir for a directory, its contents are skipped. If it returns SkipDir for a file,
+// the remaining entries of the containing directory are skipped. SkipAll stops the walk without
+// an error.
+func (x Dir) Walk(opts WalkOptions, fn WalkFunc) error {
+	err := x.walk(x, opts, newWalkIgnore(x, opts.Ignore), 1, func(entry WalkEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return fn(entry)
+	})
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+// WalkFiles calls fn for every file below this dir. Directories are traversed but not reported.
+func (x Dir) WalkFiles(opts WalkOptions, fn func(file File) error) error {
+	return x.Walk(opts, func(entry WalkEntry) error {
+		if entry.IsDir() {
+			return nil
+		}
+		return fn(entry.File())
+	})
+}
+
+// WalkSeq returns an iterator over every file and directory below this dir. The order is the same
+// as for Walk. An error reading a subdirectory is yielded together with the entry of that
+// subdirectory instead of yielding the entry on its own, its contents are skipped then. An error
+// reading this dir is yielded with an entry for this dir with depth 0. Call SkipDir on an entry to
+// not descend into it.
+func (x Dir) WalkSeq(opts WalkOptions) iter.Seq2[WalkEntry, error] {
+	return func(yield func(WalkEntry, error) bool) {
+		err := x.walk(x, opts, newWalkIgnore(x, opts.Ignore), 1, func(entry WalkEntry, err error) error {
+			if !yield(entry, err) {
+				return SkipAll
+			}
+			if entry.IsDir() && (err != nil || *entry.skip) {
+				return SkipDir
+			}
+			return nil
+		})
+		if err != nil && err != SkipAll {
+			info, _ := x.fs.Stat(x.Path())
+			yield(WalkEntry{root: x, path: x.Path(), info: info}, err)
+		}
+	}
+}
+
+// WalkFilesSeq returns an iterator over every file below this dir.
+func (x Dir) WalkFilesSeq(opts WalkOptions) iter.Seq2[File, error] {
+	return func(yield func(File, error) bool) {
+		for entry, err := range x.WalkSeq(opts) {
+			if err != nil {
+				if !yield(File{}, err) {
+					return
+				}
+				continue
+			}
+			if entry.IsDir() {
+				continue
+			}
+			if !yield(entry.File(), nil) {
+				return
+			}
+		}
+	}
+}
+
+// AllFiles returns all files below this dir in walk order.
+func (x Dir) AllFiles(opts WalkOptions) ([]File, error) {
+	files := make([]File, 0)
+	err := x.WalkFiles(opts, func(file File) error {
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// AllDirs returns all directories below this dir in walk order.
+func (x Dir) AllDirs(opts WalkOptions) ([]Dir, error) {
+	dirs := make([]Dir, 0)
+	err := x.Walk(opts, func(entry WalkEntry) error {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Dir())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+// walk visits the contents of this dir recursively. An error reading this dir is returned directly.
+func (x Dir) walk(root Dir, opts WalkOptions, ignore *walkIgnore, depth int, visit func(entry WalkEntry, err error) error) error {
+	contents, err := x.readWalkDir(root, ignore)
+	if err != nil {
+		return err
+	}
+	return x.walkContents(root, contents, opts, ignore, depth, visit)
+}
+
+// walkContents visits contents, which were read from this dir, recursively. Dirs are read before
+// they are visited, so that an error reading one is passed to visit together with its entry. Its
+// contents are skipped then.
+func (x Dir) walkContents(root Dir, contents []os.FileInfo, opts WalkOptions, ignore *walkIgnore, depth int, visit func(entry WalkEntry, err error) error) error {
+	for _, content := range contents {
+		skip := false
+		entry := WalkEntry{
+			root:  root,
+			path:  filepath.Join(x.Path(), content.Name()),
+			info:  content,
+			depth: depth,
+			skip:  &skip,
+		}
+		if ignore.ignored(entry.RelativePath(), content.IsDir()) {
+			continue
+		}
+
+		descend := content.IsDir() && (opts.MaxDepth == 0 || depth < opts.MaxDepth)
+		var children []os.FileInfo
+		var readErr error
+		if descend {
+			children, readErr = entry.Dir().readWalkDir(root, ignore)
+		}
+		err := visit(entry, readErr)
+		if err == SkipDir {
+			if content.IsDir() {
+				continue
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !descend || readErr != nil {
+			continue
+		}
+		err = entry.Dir().walkContents(root, children, opts, ignore, depth+1, visit)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readWalkDir returns the contents of this dir and loads its ignore rules.
+func (x Dir) readWalkDir(root Dir, ignore *walkIgnore) ([]os.FileInfo, error) {
+	contents, err := afero.ReadDir(x.fs, x.Path())
+	if err != nil {
+		return nil, err
+	}
+	err = ignore.enter(x.RelativeTo(root))
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
@@ -0,0 +1,161 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func newWalkTestDir(t *testing.T) Dir {
+	d := DirWithFs("/tmp/walk", afero.NewMemMapFs())
+	for _, name := range []string{"b/z.txt", "b/c/deep.txt", "a.txt", "d/e.txt", ".hidden/x"} {
+		f := d.MustFileAt(name).MustEnsureDir(0750)
+		assert.Nil(t, f.SetContentString(name))
+	}
+	return d
+}
+
+func TestDir_Walk(t *testing.T) {
+	a := assert.New(t)
+	d := newWalkTestDir(t)
+
+	paths := make([]string, 0)
+	err := d.Walk(WalkOptions{}, func(entry WalkEntry) error {
+		paths = append(paths, entry.RelativePath())
+		return nil
+	})
+	a.Nil(err)
+	a.Equal([]string{".hidden", ".hidden/x", "a.txt", "b", "b/c", "b/c/deep.txt", "b/z.txt", "d", "d/e.txt"}, paths)
+
+	// depth limit
+	paths = paths[:0]
+	err = d.Walk(WalkOptions{MaxDepth: 1}, func(entry WalkEntry) error {
+		paths = append(paths, entry.RelativePath())
+		return nil
+	})
+	a.Nil(err)
+	a.Equal([]string{".hidden", "a.txt", "b", "d"}, paths)
+
+	// skip subtree
+	paths = paths[:0]
+	err = d.Walk(WalkOptions{}, func(entry WalkEntry) error {
+		if entry.IsDir() && entry.Name() == "b" {
+			return SkipDir
+		}
+		paths = append(paths, entry.RelativePath())
+		return nil
+	})
+	a.Nil(err)
+	a.Equal([]string{".hidden", ".hidden/x", "a.txt", "d", "d/e.txt"}, paths)
+
+	// stop early
+	count := 0
+	err = d.Walk(WalkOptions{}, func(entry WalkEntry) error {
+		count++
+		return SkipAll
+	})
+	a.Nil(err)
+	a.Equal(1, count)
+}
+
+func TestDir_WalkFiles(t *testing.T) {
+	a := assert.New(t)
+	d := newWalkTestDir(t)
+
+	files, err := d.AllFiles(WalkOptions{})
+	a.Nil(err)
+	a.Len(files, 5)
+	for _, f := range files {
+		// files keep the filesystem of the walked dir
+		a.Equal(f.Path()[len("/tmp/walk/"):], f.MustContentString())
+	}
+
+	dirs, err := d.AllDirs(WalkOptions{})
+	a.Nil(err)
+	a.Len(dirs, 4)
+}
+
+func TestDir_WalkSeq(t *testing.T) {
+	a := assert.New(t)
+	d := newWalkTestDir(t)
+
+	paths := make([]string, 0)
+	for entry, err := range d.WalkSeq(WalkOptions{}) {
+		a.Nil(err)
+		if entry.Name() == "b" || entry.Name() == ".hidden" {
+			entry.SkipDir()
+		}
+		paths = append(paths, entry.RelativePath())
+	}
+	a.Equal([]string{".hidden", "a.txt", "b", "d", "d/e.txt"}, paths)
+
+	count := 0
+	for f, err := range d.WalkFilesSeq(WalkOptions{}) {
+		a.Nil(err)
+		a.True(f.Exists())
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	a.Equal(2, count)
+
+	// SkipDir on a file does not skip its siblings
+	paths = paths[:0]
+	for entry, err := range d.WalkSeq(WalkOptions{}) {
+		a.Nil(err)
+		entry.SkipDir()
+		paths = append(paths, entry.RelativePath())
+	}
+	a.Equal([]string{".hidden", "a.txt", "b", "d"}, paths)
+
+	// walking a missing dir yields the error once with an entry for the dir
+	count = 0
+	for entry, err := range d.MustDirAt("missing").WalkSeq(WalkOptions{}) {
+		a.NotNil(err)
+		a.Equal("missing", entry.Name())
+		a.False(entry.IsDir())
+		a.Equal(0, entry.Depth())
+		count++
+	}
+	a.Equal(1, count)
+
+	// an unreadable subdir is yielded once, together with the error
+	unreadable := DirWithFs(d.Path(), unreadableDirFs{d.fs, d.MustDirAt("b").Path()})
+	paths = paths[:0]
+	errs := 0
+	for entry, err := range unreadable.WalkSeq(WalkOptions{}) {
+		if err != nil {
+			a.Equal("b", entry.RelativePath())
+			errs++
+		}
+		paths = append(paths, entry.RelativePath())
+	}
+	a.Equal(1, errs)
+	a.Equal([]string{".hidden", ".hidden/x", "a.txt", "b", "d", "d/e.txt"}, paths)
+}
+
+func TestWalkEntry_Zero(t *testing.T) {
+	a := assert.New(t)
+	var entry WalkEntry
+	a.Equal("", entry.Name())
+	a.False(entry.IsDir())
+	a.Nil(entry.Info())
+	a.Equal("", entry.RelativePath())
+	a.Equal(0, entry.Depth())
+	entry.SkipDir()
+}
+
+// unreadableDirFs fails opening the dir at path.
+type unreadableDirFs struct {
+	afero.Fs
+	path string
+}
+
+func (x unreadableDirFs) Open(name string) (afero.File, error) {
+	if name == x.path {
+		return nil, os.ErrPermission
+	}
+	return x.Fs.Open(name)
+}
@@ -0,0 +1,485 @@
+package gofs
+
+import (
+	"context"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// WatchOp is the kind of change reported by Dir.Watch.
+type WatchOp int
+
+const (
+	// WatchCreated reports a new file or dir.
+	WatchCreated WatchOp = iota + 1
+	// WatchModified reports a changed file, dirs are never reported as modified.
+	WatchModified
+	// WatchRemoved reports a file or dir that does not exist anymore.
+	WatchRemoved
+	// WatchRenamed reports a file or dir that was moved inside of the watched dir.
+	WatchRenamed
+)
+
+func (x WatchOp) String() string {
+	switch x {
+	case WatchCreated:
+		return "created"
+	case WatchModified:
+		return "modified"
+	case WatchRemoved:
+		return "removed"
+	case WatchRenamed:
+		return "renamed"
+	default:
+		return "unknown"
+	}
+}
+
+// WatchOptions configures watching a Dir.
+type WatchOptions struct {
+	// Recursive watches all subdirectories too, otherwise only the direct children are watched.
+	Recursive bool
+	// Debounce delays events until no changes happened for the given duration. Multiple events for
+	// the same path are combined then, e.g. creating and modifying a file is reported as created
+	// only. 0 reports events as soon as they are noticed.
+	Debounce time.Duration
+	// Patterns restricts the reported events to paths matching these glob patterns relative to the
+	// watched dir, see Dir.Glob for the syntax. No patterns report all events.
+	Patterns []string
+	// PollInterval is the interval to scan for changes if inotify is not available, e.g. on other
+	// filesystems than the OS one. Defaults to 250ms.
+	PollInterval time.Duration
+}
+
+// WatchEvent is a change of a file or dir reported by Dir.Watch.
+type WatchEvent struct {
+	root    Dir
+	op      WatchOp
+	path    string
+	oldPath string
+	isDir   bool
+}
+
+// Op returns the kind of change.
+func (x WatchEvent) Op() WatchOp {
+	return x.op
+}
+
+// IsDir returns true if the event is about a directory.
+func (x WatchEvent) IsDir() bool {
+	return x.isDir
+}
+
+// Path returns the absolute path of the changed file or dir, the new path for renames.
+func (x WatchEvent) Path() string {
+	return x.path
+}
+
+// OldPath returns the absolute path before the change for renames and "" otherwise.
+func (x WatchEvent) OldPath() string {
+	return x.oldPath
+}
+
+// RelativePath returns the path of the changed file or dir relative to the watched Dir.
+func (x WatchEvent) RelativePath() string {
+	result, err := filepath.Rel(x.root.Path(), x.path)
+	if err != nil {
+		return x.path
+	}
+	return result
+}
+
+// File returns the changed file, using the filesystem of the watched Dir.
+func (x WatchEvent) File() File {
+	return FileWithFs(x.path, x.root.fs)
+}
+
+// Dir returns the changed dir, using the filesystem of the watched Dir.
+func (x WatchEvent) Dir() Dir {
+	return DirWithFs(x.path, x.root.fs)
+}
+
+func (x WatchEvent) String() string {
+	if x.op == WatchRenamed {
+		return x.op.String() + " " + x.oldPath + " -> " + x.path
+	}
+	return x.op.String() + " " + x.path
+}
+
+// Watch returns an iterator over the changes below this dir until ctx is done. On the OS filesystem
+// changes are noticed through inotify (or the platform's equivalent), other filesystems like
+// afero's MemMapFs are polled. Renames are detected if the filesystem provides file IDs like on unix
+// systems or the in-memory filesystem, otherwise they are reported as removal and creation. An error is yielded as last
+// element if this dir can not be read.
+func (x Dir) Watch(ctx context.Context, opts WatchOptions) iter.Seq2[WatchEvent, error] {
+	return func(yield func(WatchEvent, error) bool) {
+		var matcher *globMatcher
+		if len(opts.Patterns) > 0 {
+			var err error
+			matcher, err = compileGlob(opts.Patterns)
+			if err != nil {
+				yield(WatchEvent{}, err)
+				return
+			}
+		}
+
+		watcher := &dirWatcher{
+			root:     x,
+			opts:     opts,
+			matcher:  matcher,
+			notifier: newChangeNotifier(x.fs, opts.PollInterval),
+			pending:  make([]WatchEvent, 0),
+		}
+		defer watcher.notifier.close()
+		err := watcher.start()
+		if err != nil {
+			yield(WatchEvent{}, err)
+			return
+		}
+		watcher.run(ctx, yield)
+	}
+}
+
+// watchState is what is known about a file or dir to detect changes. The values are copied from
+// the info because infos of some filesystems reflect later changes.
+type watchState struct {
+	info    os.FileInfo
+	size    int64
+	modTime time.Time
+	mode    os.FileMode
+}
+
+func newWatchState(info os.FileInfo) watchState {
+	return watchState{
+		info:    info,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+		mode:    info.Mode(),
+	}
+}
+
+func (x watchState) changed(other watchState) bool {
+	if x.mode.IsDir() || other.mode.IsDir() {
+		return x.mode.IsDir() != other.mode.IsDir()
+	}
+	return x.size != other.size || !x.modTime.Equal(other.modTime) || x.mode != other.mode
+}
+
+// dirWatcher keeps a snapshot of the watched tree and compares rescanned parts of it against it.
+type dirWatcher struct {
+	root     Dir
+	opts     WatchOptions
+	matcher  *globMatcher
+	notifier *changeNotifier
+	snapshot map[string]watchState
+	// pending are the debounced events not reported yet.
+	pending   []WatchEvent
+	lastEvent time.Time
+}
+
+func (x *dirWatcher) start() error {
+	info, err := x.root.fs.Stat(x.root.Path())
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.NotValidf("watched path %s is not a dir", x.root)
+	}
+
+	x.notifier.add(x.root.Path())
+	x.snapshot, err = x.scan(x.root.Path())
+	if err != nil {
+		return err
+	}
+	for p, state := range x.snapshot {
+		if state.mode.IsDir() && x.opts.Recursive {
+			x.notifier.add(p)
+		}
+	}
+	return nil
+}
+
+func (x *dirWatcher) run(ctx context.Context, yield func(WatchEvent, error) bool) {
+	for {
+		timeout := x.notifier.interval
+		if x.notifier.active() {
+			// events wake up the watcher, there is no need to poll
+			timeout = time.Hour
+		}
+		if len(x.pending) > 0 {
+			timeout = min(timeout, time.Until(x.lastEvent.Add(x.opts.Debounce)))
+		}
+
+		gotEvent, ok := x.notifier.waitFor(ctx, max(timeout, 0))
+		if !ok {
+			return
+		}
+		if gotEvent {
+			// collect events arriving shortly after each other, so that renames are seen as a whole
+			for gotEvent && ok {
+				gotEvent, ok = x.notifier.waitFor(ctx, 10*time.Millisecond)
+			}
+			if !ok {
+				return
+			}
+		}
+
+		regions := []string{x.root.Path()}
+		changed, overflow := x.notifier.takeChanged()
+		if x.notifier.active() && !overflow {
+			regions = changed
+		}
+		events, err := x.rescan(regions)
+		if err != nil {
+			yield(WatchEvent{}, err)
+			return
+		}
+
+		if x.opts.Debounce <= 0 {
+			for _, event := range events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			continue
+		}
+		if len(events) > 0 {
+			x.lastEvent = time.Now()
+			for _, event := range events {
+				x.debounce(event)
+			}
+		}
+		if len(x.pending) > 0 && time.Since(x.lastEvent) >= x.opts.Debounce {
+			pending := x.pending
+			x.pending = make([]WatchEvent, 0)
+			for _, event := range pending {
+				if !yield(event, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// debounce adds event to the pending events, combining it with a pending event for the same path.
+func (x *dirWatcher) debounce(event WatchEvent) {
+	for i, previous := range x.pending {
+		if previous.path != event.path {
+			continue
+		}
+		switch {
+		case previous.op == WatchCreated && event.op == WatchRemoved:
+			x.pending = append(x.pending[:i], x.pending[i+1:]...)
+			return
+		case previous.op == WatchRemoved && event.op == WatchCreated:
+			event.op = WatchModified
+		case previous.op == WatchRenamed && event.op == WatchRemoved:
+			// the file is gone from where it was before the rename
+			event.path, event.oldPath = previous.oldPath, ""
+		case (previous.op == WatchCreated || previous.op == WatchRenamed) && event.op == WatchModified:
+			return
+		}
+		x.pending[i] = event
+		return
+	}
+	x.pending = append(x.pending, event)
+}
+
+// rescan scans the given paths and their contents again and returns the changes compared to the
+// snapshot, which is updated.
+func (x *dirWatcher) rescan(regions []string) ([]WatchEvent, error) {
+	before := make(map[string]watchState)
+	after := make(map[string]watchState)
+	scanned := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		if scanned[region] || !isPathBelow(region, x.root.Path()) {
+			continue
+		}
+		scanned[region] = true
+		for p, state := range x.snapshot {
+			if isPathBelow(p, region) {
+				before[p] = state
+			}
+		}
+		states, err := x.scan(region)
+		if err != nil {
+			return nil, err
+		}
+		for p, state := range states {
+			after[p] = state
+		}
+	}
+
+	for p := range before {
+		delete(x.snapshot, p)
+	}
+	for p, state := range after {
+		x.snapshot[p] = state
+		if _, existed := before[p]; !existed && state.mode.IsDir() && x.opts.Recursive {
+			x.notifier.add(p)
+		}
+	}
+	for p, state := range before {
+		if _, exists := after[p]; !exists && state.mode.IsDir() {
+			x.notifier.remove(p)
+		}
+	}
+	return x.diff(before, after), nil
+}
+
+// scan returns the states of p and everything below it that is watched. Paths that do not exist
+// are skipped.
+func (x *dirWatcher) scan(p string) (map[string]watchState, error) {
+	result := make(map[string]watchState)
+	if p != x.root.Path() {
+		if !x.watched(p) {
+			return result, nil
+		}
+		info, err := lstat(x.root.fs, p)
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[p] = newWatchState(info)
+		if !info.IsDir() || !x.opts.Recursive {
+			return result, nil
+		}
+	}
+
+	walkOptions := WalkOptions{}
+	if !x.opts.Recursive {
+		walkOptions.MaxDepth = 1
+	}
+	err := DirWithFs(p, x.root.fs).Walk(walkOptions, func(entry WalkEntry) error {
+		result[entry.Path()] = newWatchState(entry.Info())
+		return nil
+	})
+	if os.IsNotExist(errors.Cause(err)) {
+		// removed while scanning, the next scan notices
+		return result, nil
+	}
+	return result, err
+}
+
+// watched returns if changes of p are watched, which depends on the recursion setting.
+func (x *dirWatcher) watched(p string) bool {
+	return p != x.root.Path() && isPathBelow(p, x.root.Path()) &&
+		(x.opts.Recursive || filepath.Dir(p) == x.root.Path())
+}
+
+// diff returns the events that turn before into after, sorted by path.
+func (x *dirWatcher) diff(before, after map[string]watchState) []WatchEvent {
+	created := make([]string, 0)
+	removed := make([]string, 0)
+	events := make([]WatchEvent, 0)
+	for p, state := range after {
+		old, existed := before[p]
+		switch {
+		case !existed:
+			created = append(created, p)
+		case state.changed(old) && old.mode.IsDir() != state.mode.IsDir():
+			removed = append(removed, p)
+			created = append(created, p)
+		case state.changed(old):
+			events = append(events, x.event(WatchModified, p, "", state.mode.IsDir()))
+		}
+	}
+	for p := range before {
+		if _, exists := after[p]; !exists {
+			removed = append(removed, p)
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(removed)
+
+	// pair removed and created paths of the same file to renames, the contents of renamed dirs are
+	// not reported separately
+	renamedFrom := make(map[string]string)
+	renamedDirs := make(map[string]string)
+	for _, newPath := range created {
+		if _, ok := renamedFrom[newPath]; ok {
+			continue
+		}
+		for i, oldPath := range removed {
+			if same, known := fileIdentity(before[oldPath].info, after[newPath].info); !known || !same {
+				continue
+			}
+			renamedFrom[newPath] = oldPath
+			removed = append(removed[:i], removed[i+1:]...)
+			if after[newPath].mode.IsDir() {
+				renamedDirs[oldPath] = newPath
+			}
+			break
+		}
+	}
+
+	for _, p := range created {
+		oldPath, renamed := renamedFrom[p]
+		switch {
+		case renamed && isInRenamedDir(oldPath, p, renamedDirs):
+		case renamed:
+			events = append(events, x.event(WatchRenamed, p, oldPath, after[p].mode.IsDir()))
+		default:
+			events = append(events, x.event(WatchCreated, p, "", after[p].mode.IsDir()))
+		}
+	}
+	for _, p := range removed {
+		events = append(events, x.event(WatchRemoved, p, "", before[p].mode.IsDir()))
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].path < events[j].path
+	})
+
+	// apply the patterns
+	result := make([]WatchEvent, 0, len(events))
+	for _, event := range events {
+		if x.matches(event.path) || (event.oldPath != "" && x.matches(event.oldPath)) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+func (x *dirWatcher) event(op WatchOp, p, oldPath string, isDir bool) WatchEvent {
+	return WatchEvent{
+		root:    x.root,
+		op:      op,
+		path:    p,
+		oldPath: oldPath,
+		isDir:   isDir,
+	}
+}
+
+func (x *dirWatcher) matches(p string) bool {
+	if x.matcher == nil {
+		return true
+	}
+	rel, err := filepath.Rel(x.root.Path(), p)
+	if err != nil {
+		return false
+	}
+	return x.matcher.Match(filepath.ToSlash(rel))
+}
+
+// isInRenamedDir returns true if the rename of oldPath to newPath is the result of renaming one of
+// its parent dirs.
+func isInRenamedDir(oldPath, newPath string, renamedDirs map[string]string) bool {
+	for oldDir, newDir := range renamedDirs {
+		if oldDir == oldPath {
+			continue
+		}
+		if strings.HasPrefix(oldPath, oldDir+string(filepath.Separator)) &&
+			newPath == newDir+strings.TrimPrefix(oldPath, oldDir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package gofs
+
+import (
+	"context"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// watchEvents watches d in the background and sends the events as strings with paths relative to
+// d to the returned channel.
+func watchEvents(t *testing.T, d Dir, opts WatchOptions) <-chan string {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := make(chan string, 100)
+	go func() {
+		defer close(events)
+		for event, err := range d.Watch(ctx, opts) {
+			if err != nil {
+				events <- "error: " + err.Error()
+				return
+			}
+			description := event.Op().String() + " " + filepath.ToSlash(event.RelativePath())
+			if event.Op() == WatchRenamed {
+				oldPath, _ := filepath.Rel(d.Path(), event.OldPath())
+				description = event.Op().String() + " " + filepath.ToSlash(oldPath) + " -> " + filepath.ToSlash(event.RelativePath())
+			}
+			if event.IsDir() {
+				description += "/"
+			}
+			events <- description
+		}
+	}()
+	// give the watcher time to take its initial snapshot
+	time.Sleep(100 * time.Millisecond)
+	return events
+}
+
+func receiveEvents(t *testing.T, events <-chan string, n int) []string {
+	t.Helper()
+	result := make([]string, 0, n)
+	timeout := time.After(5 * time.Second)
+	for len(result) < n {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("watch ended after %v", result)
+			}
+			result = append(result, event)
+		case <-timeout:
+			t.Fatalf("timeout after %v", result)
+		}
+	}
+	// no further events
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %s after %v", event, result)
+	case <-time.After(100 * time.Millisecond):
+	}
+	return result
+}
+
+func testWatch(t *testing.T, d Dir) {
+	a := assert.New(t)
+	opts := WatchOptions{Recursive: true, PollInterval: 10 * time.Millisecond}
+	a.Nil(d.MustFileAt("existing.txt").SetContentString("existing"))
+	events := watchEvents(t, d, opts)
+
+	a.Nil(d.MustFileAt("new.txt").SetContentString("new"))
+	a.Equal([]string{"created new.txt"}, receiveEvents(t, events, 1))
+
+	a.Nil(d.MustFileAt("existing.txt").SetContentString("modified content"))
+	a.Equal([]string{"modified existing.txt"}, receiveEvents(t, events, 1))
+
+	a.Nil(d.fs.MkdirAll(filepath.Join(d.Path(), "sub", "deeper"), 0750))
+	a.Equal([]string{"created sub/", "created sub/deeper/"}, receiveEvents(t, events, 2))
+	a.Nil(d.MustFileAt("sub/deeper/file.txt").SetContentString("deep"))
+	a.Equal([]string{"created sub/deeper/file.txt"}, receiveEvents(t, events, 1))
+
+	a.Nil(d.fs.Rename(filepath.Join(d.Path(), "new.txt"), filepath.Join(d.Path(), "sub", "renamed.txt")))
+	a.Equal([]string{"renamed new.txt -> sub/renamed.txt"}, receiveEvents(t, events, 1))
+
+	a.Nil(d.MustFileAt("existing.txt").Remove())
+	a.Equal([]string{"removed existing.txt"}, receiveEvents(t, events, 1))
+
+	a.Nil(d.fs.RemoveAll(filepath.Join(d.Path(), "sub")))
+	a.Equal([]string{"removed sub/", "removed sub/deeper/", "removed sub/deeper/file.txt", "removed sub/renamed.txt"}, receiveEvents(t, events, 4))
+}
+
+func TestDir_Watch(t *testing.T) {
+	d := DirWithFs("/tmp/watched", afero.NewMemMapFs())
+	assert.Nil(t, d.Create(0750))
+	testWatch(t, d)
+}
+
+func TestDir_Watch_OsFs(t *testing.T) {
+	testWatch(t, DirWithFs(t.TempDir(), afero.NewOsFs()))
+}
+
+func TestDir_Watch_Options(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs("/tmp/watched", afero.NewMemMapFs())
+	a.Nil(d.fs.MkdirAll(filepath.Join(d.Path(), "sub"), 0750))
+
+	// not recursive, filtered
+	events := watchEvents(t, d, WatchOptions{Patterns: []string{"*.log"}, PollInterval: 10 * time.Millisecond})
+	a.Nil(d.MustFileAt("sub/ignored.log").SetContentString("ignored"))
+	a.Nil(d.MustFileAt("ignored.txt").SetContentString("ignored"))
+	a.Nil(d.MustFileAt("app.log").SetContentString("reported"))
+	a.Equal([]string{"created app.log"}, receiveEvents(t, events, 1))
+
+	// debounced
+	a.Nil(d.MustFileAt("moved.txt").SetContentString("moved"))
+	events = watchEvents(t, d, WatchOptions{Debounce: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+	f := d.MustFileAt("debounced.txt")
+	a.Nil(f.SetContentString("x"))
+	for i := 0; i < 5; i++ {
+		time.Sleep(20 * time.Millisecond)
+		a.Nil(f.AppendString("x"))
+	}
+	a.Nil(d.MustFileAt("temp.txt").SetContentString("temp"))
+	time.Sleep(20 * time.Millisecond)
+	a.Nil(d.MustFileAt("temp.txt").Remove())
+	a.Nil(d.fs.Rename(d.MustFileAt("moved.txt").Path(), d.MustFileAt("moved2.txt").Path()))
+	time.Sleep(20 * time.Millisecond)
+	a.Nil(d.MustFileAt("moved2.txt").Remove())
+	a.Equal([]string{"created debounced.txt", "removed moved.txt"}, receiveEvents(t, events, 2))
+
+	// errors
+	for event, err := range DirWithFs("/tmp/missing", d.fs).Watch(context.Background(), WatchOptions{}) {
+		a.Equal(WatchEvent{}, event)
+		a.ErrorIs(err, ErrNotExist)
+	}
+	for _, err := range d.Watch(context.Background(), WatchOptions{Patterns: []string{"[invalid"}}) {
+		a.Error(err)
+	}
+}
@@ -0,0 +1,68 @@
+package gofs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+var (
+	// ErrNotExist is returned if a file or dir does not exist. It is the same as fs.ErrNotExist.
+	ErrNotExist = fs.ErrNotExist
+	// ErrExist is returned if a file or dir exists. It is the same as fs.ErrExist.
+	ErrExist = fs.ErrExist
+	// ErrEmpty is returned if a file or dir is empty.
+	ErrEmpty = errors.New("empty")
+	// ErrNotEmpty is returned if a file or dir is not empty.
+	ErrNotEmpty = errors.New("not empty")
+	// ErrNotReadable is returned if a file or dir can not be read.
+	ErrNotReadable = errors.New("not readable")
+	// ErrNotWritable is returned if a file or dir can not be written.
+	ErrNotWritable = errors.New("not writable")
+	// ErrExtensionMismatch is returned if a file does not have the expected extension.
+	ErrExtensionMismatch = errors.New("file extension mismatch")
+	// ErrHashMismatch is returned if the content of a file does not have the expected hash.
+	ErrHashMismatch = errors.New("hash mismatch")
+	// ErrPathNotRelative is returned if a relative path was expected.
+	ErrPathNotRelative = errors.New("path not relative")
+	// ErrUnsafeArchivePath is returned if an archive entry would be extracted outside of the target dir.
+	ErrUnsafeArchivePath = errors.New("unsafe archive path")
+	// ErrArchiveTooLarge is returned if extracting an archive exceeds the configured size limit.
+	ErrArchiveTooLarge = errors.New("archive too large")
+	// ErrLocked is returned if a file lock can not be acquired without waiting.
+	ErrLocked = errors.New("locked")
+	// ErrProcessRunning is returned if a PID file belongs to a process that is still running.
+	ErrProcessRunning = errors.New("process running")
+	// ErrUnsupported is returned if the filesystem backend does not support an operation. It is the
+	// same as errors.ErrUnsupported.
+	ErrUnsupported = errors.ErrUnsupported
+)
+
+// AssertionError is returned if a file or dir does not meet an expectation. Use errors.Is with one
+// of the sentinel errors to find out which expectation failed.
+type AssertionError struct {
+	path    string
+	kind    error
+	message string
+}
+
+func NewAssertionError(kind error, path string, format string, args ...any) *AssertionError {
+	return &AssertionError{
+		path:    path,
+		kind:    kind,
+		message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (x AssertionError) Error() string {
+	return x.message
+}
+
+// Path returns the path of the file or dir that did not meet the expectation.
+func (x AssertionError) Path() string {
+	return x.path
+}
+
+func (x AssertionError) Unwrap() error {
+	return x.kind
+}
@@ -0,0 +1,104 @@
+package gofs
+
+import (
+	"errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+// unreadableFs fails opening any file.
+type unreadableFs struct {
+	afero.Fs
+}
+
+func (x unreadableFs) Open(name string) (afero.File, error) {
+	return nil, os.ErrPermission
+}
+
+// unstatableFs fails getting the info of any file.
+type unstatableFs struct {
+	afero.Fs
+}
+
+func (x unstatableFs) Stat(name string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrPermission}
+}
+
+func TestCheckErrors(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	f := FileWithFs("/tmp/errors/file.log", fs)
+	d := DirWithFs("/tmp/errors", fs)
+
+	var assertionErr *AssertionError
+	err := f.CheckExists()
+	a.ErrorAs(err, &assertionErr)
+	a.Equal(f.Path(), assertionErr.Path())
+	a.ErrorIs(err, ErrNotExist)
+	a.ErrorIs(err, os.ErrNotExist)
+	a.ErrorIs(d.CheckExists(), ErrNotExist)
+
+	a.Nil(f.SetContentString("content"))
+	a.Nil(f.CheckExists())
+	a.ErrorIs(f.CheckNotExists(), ErrExist)
+	a.ErrorIs(f.CheckEmpty(), ErrNotEmpty)
+	a.ErrorIs(f.CheckExtension(ExtPdf), ErrExtensionMismatch)
+	a.ErrorIs(f.CheckMd5Hash("invalid"), ErrHashMismatch)
+	a.ErrorIs(d.CheckEmpty(), ErrNotEmpty)
+	a.ErrorIs(d.CheckNotExists(), ErrExist)
+	a.ErrorIs(d.MustDirAt("empty").MustEnsure(0750).CheckNotEmpty(), ErrEmpty)
+
+	readOnly := f.WithFs(afero.NewReadOnlyFs(fs))
+	writable, err := readOnly.IsWritableE()
+	a.Nil(err)
+	a.False(writable)
+	a.ErrorIs(readOnly.CheckWritable(), ErrNotWritable)
+
+	_, err = d.FileAt("/absolute")
+	a.ErrorIs(err, ErrPathNotRelative)
+	var relativeErr *FilePathNotRelativeError
+	a.True(errors.As(err, &relativeErr))
+
+	// assertions panic with the same errors
+	a.PanicsWithError(f.CheckEmpty().Error(), func() {
+		f.AssertEmpty()
+	})
+}
+
+func TestErrorReturningQueries(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	f := FileWithFs("/tmp/queries/file.log", fs)
+	a.Nil(f.SetContentString("12345"))
+
+	size, err := f.FilesizeE()
+	a.Nil(err)
+	a.Equal(int64(5), size)
+	human, err := f.FilesizeHumanE()
+	a.Nil(err)
+	a.Equal("5 B", human)
+
+	unreadable := f.WithFs(unreadableFs{fs})
+	_, err = unreadable.FilesizeE()
+	a.ErrorIs(err, ErrNotReadable)
+	_, err = unreadable.IsEmptyE()
+	a.ErrorIs(err, ErrNotReadable)
+	a.Panics(func() {
+		unreadable.Filesize()
+	})
+	_, err = f.WithFs(unstatableFs{fs}).FilesizeE()
+	a.ErrorIs(err, os.ErrPermission)
+
+	newFile, err := NewFileWithFs("relative.txt", fs)
+	a.Nil(err)
+	a.True(newFile.Equals(WorkingDir().MustFileAt("relative.txt")))
+	newDir, err := NewDir("/tmp/queries/")
+	a.Nil(err)
+	a.Equal("/tmp/queries", newDir.Path())
+
+	empty, err := DirWithFs("/tmp/missing", fs).IsEmptyE()
+	a.Nil(err)
+	a.True(empty)
+}
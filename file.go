@@ -2,6 +2,7 @@ package gofs
 
 import (
 	"fmt"
+	"github.com/juju/errors"
 	"github.com/spf13/afero"
 	"os"
 	"path"
@@ -18,6 +19,8 @@ type File struct {
 	path              string
 	createPermissions os.FileMode
 	fs                afero.Fs
+	// compressed enables transparent compression according to the file extension.
+	compressed bool
 }
 
 func FileAt(filePath string) File {
@@ -26,7 +29,7 @@ func FileAt(filePath string) File {
 
 func FileAtDir(dir Dir, filename string) File {
 	filePath := filepath.Join(dir.Path(), filename)
-	return FileWithFs(filePath, afero.NewOsFs())
+	return FileWithFs(filePath, dir.fs)
 }
 
 func fileWithSameFs(filePath string, f File) File {
@@ -34,28 +37,63 @@ func fileWithSameFs(filePath string, f File) File {
 }
 
 func FileWithFs(filePath string, fs afero.Fs) File {
-	// replace home dir path
-	filePath, err := homedir.Expand(filePath)
+	file, err := NewFileWithFs(filePath, fs)
 	if err != nil {
 		// this should really not happen
 		panic(err)
 	}
+	return file
+}
 
-	// make path absolute
-	if !filepath.IsAbs(filePath) {
-		pwd, err := os.Getwd()
-		if err != nil {
-			// this should not happen
-			panic(err)
-		}
-		filePath = filepath.Join(pwd, filePath)
+// NewFile returns the file at filePath on the OS filesystem. In contrast to FileAt, an error is
+// returned if the path can not be made absolute.
+func NewFile(filePath string) (File, error) {
+	return NewFileWithFs(filePath, afero.NewOsFs())
+}
+
+// NewFileWithFs returns the file at filePath on fs. In contrast to FileWithFs, an error is returned
+// if the path can not be made absolute.
+func NewFileWithFs(filePath string, fs afero.Fs) (File, error) {
+	filePath, err := absolutePath(filePath)
+	if err != nil {
+		return File{}, err
 	}
 
 	return File{
 		path:              filePath,
 		createPermissions: 0640,
 		fs:                fs,
+	}, nil
+}
+
+// absolutePath expands the home dir and makes path absolute.
+func absolutePath(path string) (string, error) {
+	// replace home dir path
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", errors.Annotatef(err, "could not expand home dir in %s", path)
+	}
+
+	// make path absolute
+	if !filepath.IsAbs(path) {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return "", errors.Annotatef(err, "could not make %s absolute", path)
+		}
+		path = filepath.Join(pwd, path)
 	}
+	return path, nil
+}
+
+// WithFs returns this file on another filesystem, keeping the path.
+func (x File) WithFs(fs afero.Fs) File {
+	x.fs = fs
+	return x
+}
+
+// Fs returns the filesystem this file lives on.
+func (x File) Fs() afero.Fs {
+	return x.fs
 }
 
 // SetCreatePermissions allows you to define the FileMode used when creating this file (if it did not exist).
@@ -65,15 +103,32 @@ func (x File) SetCreatePermissions(perm os.FileMode) File {
 }
 
 func (x File) Exists() bool {
+	exists, _ := x.ExistsE()
+	return exists
+}
+
+// ExistsE returns if this file exists. Errors other than the file not existing are returned.
+func (x File) ExistsE() (bool, error) {
 	fi, err := x.fs.Stat(x.Path())
-	return !os.IsNotExist(err) && !fi.IsDir()
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !fi.IsDir(), nil
 }
 
 func (x File) AssertExists() File {
+	return mustCheck(x, x.CheckExists())
+}
+
+// CheckExists returns an AssertionError wrapping ErrNotExist if this file does not exist.
+func (x File) CheckExists() error {
 	if !x.Exists() {
-		panic(fmt.Errorf("file %s should have existed", x))
+		return NewAssertionError(ErrNotExist, x.path, "file %s should have existed", x)
 	}
-	return x
+	return nil
 }
 
 func (x File) NotExists() bool {
@@ -81,10 +136,15 @@ func (x File) NotExists() bool {
 }
 
 func (x File) AssertNotExists() File {
+	return mustCheck(x, x.CheckNotExists())
+}
+
+// CheckNotExists returns an AssertionError wrapping ErrExist if this file exists.
+func (x File) CheckNotExists() error {
 	if !x.NotExists() {
-		panic(fmt.Errorf("file %s should not have existed", x))
+		return NewAssertionError(ErrExist, x.path, "file %s should not have existed", x)
 	}
-	return x
+	return nil
 }
 
 func (x File) Path() string {
@@ -136,25 +196,51 @@ func (x File) OpenStandard() error {
 }
 
 func (x File) Filesize() int64 {
-	if !x.Exists() {
-		return 0
+	size, err := x.FilesizeE()
+	if err != nil {
+		panic(err)
 	}
-	if !x.IsReadable() {
-		panic(fmt.Errorf("file %s was not readable to check filesize", x))
+	return size
+}
+
+// FilesizeE returns the size of this file in bytes, 0 if it does not exist. An AssertionError
+// wrapping ErrNotReadable is returned if the file can not be read.
+func (x File) FilesizeE() (int64, error) {
+	exists, err := x.ExistsE()
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+	err = x.CheckReadable()
+	if err != nil {
+		return 0, err
 	}
 	fi, err := x.fs.Stat(x.Path())
 	if err != nil {
-		// this should not happen
-		panic(err)
+		return 0, err
 	}
-	return fi.Size()
+	return fi.Size(), nil
 }
 
 func (x File) FilesizeHuman() string {
+	human, err := x.FilesizeHumanE()
+	if err != nil {
+		panic(err)
+	}
+	return human
+}
+
+// FilesizeHumanE returns the size of this file in a human readable form like "1.5 KiB".
+func (x File) FilesizeHumanE() (string, error) {
 	const unit = 1024
-	b := x.Filesize()
+	b, err := x.FilesizeE()
+	if err != nil {
+		return "", err
+	}
 	if b < unit {
-		return fmt.Sprintf("%d B", b)
+		return fmt.Sprintf("%d B", b), nil
 	}
 	div, exp := int64(unit), 0
 	for n := b / unit; n >= unit; n /= unit {
@@ -162,25 +248,49 @@ func (x File) FilesizeHuman() string {
 		exp++
 	}
 	return fmt.Sprintf("%.1f %ciB",
-		float64(b)/float64(div), "KMGTPE"[exp])
+		float64(b)/float64(div), "KMGTPE"[exp]), nil
 }
 
 func (x File) IsEmpty() bool {
 	return x.Filesize() == 0
 }
 
+// IsEmptyE returns if this file has no content. A file that does not exist is empty.
+func (x File) IsEmptyE() (bool, error) {
+	size, err := x.FilesizeE()
+	return size == 0, err
+}
+
 func (x File) AssertEmpty() File {
-	if !x.IsEmpty() {
-		panic(fmt.Errorf("file %s should have been empty", x))
+	return mustCheck(x, x.CheckEmpty())
+}
+
+// CheckEmpty returns an AssertionError wrapping ErrNotEmpty if this file has content.
+func (x File) CheckEmpty() error {
+	empty, err := x.IsEmptyE()
+	if err != nil {
+		return err
 	}
-	return x
+	if !empty {
+		return NewAssertionError(ErrNotEmpty, x.path, "file %s should have been empty", x)
+	}
+	return nil
 }
 
 func (x File) AssertNotEmpty() File {
-	if x.IsEmpty() {
-		panic(fmt.Errorf("file %s should not have been empty", x))
+	return mustCheck(x, x.CheckNotEmpty())
+}
+
+// CheckNotEmpty returns an AssertionError wrapping ErrEmpty if this file has no content.
+func (x File) CheckNotEmpty() error {
+	empty, err := x.IsEmptyE()
+	if err != nil {
+		return err
 	}
-	return x
+	if empty {
+		return NewAssertionError(ErrEmpty, x.path, "file %s should not have been empty", x)
+	}
+	return nil
 }
 
 func (x File) WithFileReadOnly(logic func(f afero.File) error) error {
@@ -198,3 +308,11 @@ func (x File) WithFileReadOnly(logic func(f afero.File) error) error {
 	}
 	return closeErr
 }
+
+// mustCheck panics if err is not nil, otherwise it returns value.
+func mustCheck[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
@@ -0,0 +1,103 @@
+package gofs
+
+import (
+	"os"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// AtomicSetContent replaces the content of this file so that readers either see the old or the new
+// content, never a partially written file. The content is written to a temporary file next to this
+// file, synced and renamed over it. The mode of an existing file is kept, new files are created
+// using the create permissions. The content is compressed if transparent compression is enabled.
+func (x File) AtomicSetContent(newContent []byte) error {
+	newContent, err := x.encode(newContent)
+	if err != nil {
+		return err
+	}
+
+	mode := x.createPermissions
+	if info, err := x.fs.Stat(x.Path()); err == nil {
+		mode = info.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+	}
+
+	dirPath := x.Dir().Path()
+	tmp, err := afero.TempFile(x.fs, dirPath, "."+x.Filename()+".tmp-*")
+	if err != nil {
+		return errors.Annotatef(err, "could not create temp file for %s", x)
+	}
+	tmpPath := tmp.Name()
+
+	err = writeAndSync(tmp, newContent)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = x.fs.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = x.fs.Rename(tmpPath, x.Path())
+	}
+	if err != nil {
+		_ = x.fs.Remove(tmpPath)
+		return errors.Annotatef(err, "could not atomically write %s", x)
+	}
+
+	return syncDir(x.fs, dirPath)
+}
+
+func (x File) AtomicSetContentString(newContent string) error {
+	return x.AtomicSetContent([]byte(newContent))
+}
+
+func (x File) MustAtomicSetContent(newContent []byte) File {
+	err := x.AtomicSetContent(newContent)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+func writeAndSync(f afero.File, content []byte) error {
+	_, err := f.Write(content)
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// syncDir makes a rename inside of dirPath durable. Only dirs on the OS filesystem are synced, also
+// if it is wrapped, e.g. by a BasePathFs.
+func syncDir(fs afero.Fs, dirPath string) error {
+	d, err := fs.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	osDir := osFile(d)
+	if osDir == nil {
+		return nil
+	}
+	err = osDir.Sync()
+	if err != nil {
+		return errors.Annotatef(err, "could not sync dir %s", dirPath)
+	}
+	return nil
+}
+
+// osFile returns the file of the OS filesystem behind f, nil if f is not backed by one.
+func osFile(f afero.File) *os.File {
+	for {
+		switch file := f.(type) {
+		case *os.File:
+			return file
+		case *afero.BasePathFile:
+			f = file.File
+		default:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestFile_AtomicSetContent(t *testing.T) {
+	for name, dir := range map[string]Dir{
+		"memory":    DirWithFs("/tmp/atomic", afero.NewMemMapFs()).MustEnsure(0750),
+		"os":        DirWithFs(t.TempDir(), afero.NewOsFs()),
+		"base path": DirWithFs("/atomic", afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())).MustEnsure(0750),
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := assert.New(t)
+			f := dir.MustFileAt("state.json").SetCreatePermissions(0604)
+
+			a.Nil(f.AtomicSetContentString("first"))
+			a.Equal("first", f.MustContentString())
+			info, err := dir.fs.Stat(f.Path())
+			a.Nil(err)
+			a.Equal(os.FileMode(0604), info.Mode().Perm())
+
+			// the existing mode is kept
+			a.Nil(dir.fs.Chmod(f.Path(), 0600))
+			a.Nil(f.AtomicSetContentString("second"))
+			a.Equal("second", f.MustContentString())
+			info, err = dir.fs.Stat(f.Path())
+			a.Nil(err)
+			a.Equal(os.FileMode(0600), info.Mode().Perm())
+
+			// special mode bits are kept too
+			a.Nil(dir.fs.Chmod(f.Path(), 0600|os.ModeSetuid))
+			a.Nil(f.AtomicSetContentString("third"))
+			info, err = dir.fs.Stat(f.Path())
+			a.Nil(err)
+			a.Equal(0600|os.ModeSetuid, info.Mode()&(os.ModePerm|os.ModeSetuid))
+
+			// no temp files are left behind
+			files, err := dir.Files()
+			a.Nil(err)
+			a.Len(files, 1)
+		})
+	}
+}
+
+func TestOsFile(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+	for _, fs := range []afero.Fs{afero.NewOsFs(), afero.NewBasePathFs(afero.NewOsFs(), dir)} {
+		f, err := fs.Open("/")
+		a.Nil(err)
+		a.NotNil(osFile(f))
+		a.Nil(f.Close())
+	}
+	f, err := afero.NewMemMapFs().Open("/")
+	a.Nil(err)
+	a.Nil(osFile(f))
+}
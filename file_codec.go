@@ -0,0 +1,96 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+)
+
+// ReadJSON parses the content of this file as JSON into the value pointed to by v.
+func (x File) ReadJSON(v any) error {
+	return x.DecodeWith(JSONCodec{}, v)
+}
+
+// WriteJSON replaces the content of this file with v encoded as JSON. indent is the number of
+// spaces used to indent nested values, 0 writes compact JSON.
+func (x File) WriteJSON(v any, indent int) error {
+	return x.EncodeWith(JSONCodec{Indent: indent}, v)
+}
+
+// ReadYAML parses the content of this file as YAML into the value pointed to by v.
+func (x File) ReadYAML(v any) error {
+	return x.DecodeWith(YAMLCodec{}, v)
+}
+
+// WriteYAML replaces the content of this file with v encoded as YAML. indent is the number of spaces
+// used to indent nested values, 0 uses 2.
+func (x File) WriteYAML(v any, indent int) error {
+	return x.EncodeWith(YAMLCodec{Indent: indent}, v)
+}
+
+// ReadTOML parses the content of this file as TOML into the value pointed to by v.
+func (x File) ReadTOML(v any) error {
+	return x.DecodeWith(TOMLCodec{}, v)
+}
+
+// WriteTOML replaces the content of this file with v encoded as TOML. indent is the number of spaces
+// used to indent nested tables.
+func (x File) WriteTOML(v any, indent int) error {
+	return x.EncodeWith(TOMLCodec{Indent: indent}, v)
+}
+
+// Decode parses the content of this file into the value pointed to by v using the codec registered
+// for its file extension (see CodecFor).
+func (x File) Decode(v any) error {
+	codec, err := x.Codec()
+	if err != nil {
+		return err
+	}
+	return x.DecodeWith(codec, v)
+}
+
+// Encode replaces the content of this file with v encoded by the codec registered for its file
+// extension (see CodecFor).
+func (x File) Encode(v any) error {
+	codec, err := x.Codec()
+	if err != nil {
+		return err
+	}
+	return x.EncodeWith(codec, v)
+}
+
+// DecodeWith parses the content of this file into the value pointed to by v using codec.
+func (x File) DecodeWith(codec Codec, v any) error {
+	content, err := x.Content()
+	if err != nil {
+		return err
+	}
+	err = codec.Decode(content, v)
+	if err != nil {
+		return errors.Annotatef(err, "could not decode %s as %s", x, codec.Name())
+	}
+	return nil
+}
+
+// EncodeWith replaces the content of this file with v encoded by codec. The file is written like
+// SetContent does, so new files get the create permissions.
+func (x File) EncodeWith(codec Codec, v any) error {
+	content, err := codec.Encode(v)
+	if err != nil {
+		return errors.Annotatef(err, "could not encode %s for %s", codec.Name(), x)
+	}
+	return x.SetContent(content)
+}
+
+// Codec returns the codec registered for the file extension of this file. If transparent
+// compression is enabled, the extension before the compression one is used, e.g. "json" for
+// "config.json.gz".
+func (x File) Codec() (Codec, error) {
+	f := x
+	if x.IsCompressed() {
+		f = x.WithoutExtension()
+	}
+	extension := f.Extension()
+	if extension == nil {
+		return nil, errors.NotFoundf("codec for %s without file extension", x)
+	}
+	return CodecFor(*extension)
+}
@@ -0,0 +1,89 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type codecTestConfig struct {
+	Name    string            `json:"name" yaml:"name" toml:"name"`
+	Port    int               `json:"port" yaml:"port" toml:"port"`
+	Tags    []string          `json:"tags" yaml:"tags" toml:"tags"`
+	Headers map[string]string `json:"headers" yaml:"headers" toml:"headers"`
+}
+
+func TestFile_Codecs(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	config := codecTestConfig{
+		Name:    "gofs & friends",
+		Port:    8080,
+		Tags:    []string{"a", "b"},
+		Headers: map[string]string{"X-Test": "1"},
+	}
+
+	f := FileWithFs("/tmp/config.json", fs)
+	a.Nil(f.WriteJSON(config, 2))
+	a.Equal("{\n  \"name\": \"gofs & friends\",\n  \"port\": 8080,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ],\n  \"headers\": {\n    \"X-Test\": \"1\"\n  }\n}\n", f.MustContentString())
+	var fromJSON codecTestConfig
+	a.Nil(f.ReadJSON(&fromJSON))
+	a.Equal(config, fromJSON)
+	a.Nil(f.WriteJSON(config, 0))
+	a.Equal("{\"name\":\"gofs & friends\",\"port\":8080,\"tags\":[\"a\",\"b\"],\"headers\":{\"X-Test\":\"1\"}}\n", f.MustContentString())
+
+	f = FileWithFs("/tmp/config.yaml", fs)
+	a.Nil(f.WriteYAML(config, 0))
+	a.Equal("name: gofs & friends\nport: 8080\ntags:\n  - a\n  - b\nheaders:\n  X-Test: \"1\"\n", f.MustContentString())
+	var fromYAML codecTestConfig
+	a.Nil(f.ReadYAML(&fromYAML))
+	a.Equal(config, fromYAML)
+
+	f = FileWithFs("/tmp/config.toml", fs)
+	a.Nil(f.WriteTOML(config, 0))
+	a.Equal("name = \"gofs & friends\"\nport = 8080\ntags = [\"a\", \"b\"]\n\n[headers]\nX-Test = \"1\"\n", f.MustContentString())
+	var fromTOML codecTestConfig
+	a.Nil(f.ReadTOML(&fromTOML))
+	a.Equal(config, fromTOML)
+
+	// decoding errors mention the file
+	a.Nil(f.SetContentString("{"))
+	err := f.ReadJSON(&fromJSON)
+	a.ErrorContains(err, "/tmp/config.toml")
+	a.ErrorContains(err, "json")
+}
+
+func TestFile_Encode(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	config := codecTestConfig{Name: "gofs", Port: 1, Tags: []string{}, Headers: map[string]string{}}
+
+	for _, name := range []string{"/tmp/c.json", "/tmp/c.yaml", "/tmp/c.YML", "/tmp/c.toml", "/tmp/c.json.gz"} {
+		f := FileWithFs(name, fs).Compressed().SetCreatePermissions(0600)
+		a.Nil(f.Encode(config), name)
+		var decoded codecTestConfig
+		a.Nil(f.Decode(&decoded), name)
+		a.Equal(config, decoded, name)
+
+		info, err := fs.Stat(name)
+		a.Nil(err)
+		a.Equal(0600, int(info.Mode().Perm()), name)
+	}
+	a.True(FileWithFs("/tmp/c.json.gz", fs).MustContent()[0] == 0x1f)
+
+	codec, err := FileWithFs("/tmp/c.yml", fs).Codec()
+	a.Nil(err)
+	a.Equal("yaml", codec.Name())
+
+	_, err = FileWithFs("/tmp/c.ini", fs).Codec()
+	a.Error(err)
+	a.Error(FileWithFs("/tmp/c", fs).Encode(config))
+
+	RegisterCodec(FileExtensionFrom("ini"), JSONCodec{})
+	defer func() {
+		codecsMutex.Lock()
+		delete(codecs, "ini")
+		codecsMutex.Unlock()
+	}()
+	a.Nil(FileWithFs("/tmp/c.ini", fs).Encode(config))
+}
@@ -0,0 +1,124 @@
+package gofs
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// Compressed returns this file with transparent compression enabled: if its filename ends in .gz,
+// .zst, .xz or .bz2, Content, ContentString and Reader decompress and SetContent, Append and Writer
+// compress its content. Files with other extensions are read and written as is, so the same code
+// handles plain and compressed files, e.g. "app.log" and "app.log.gz". bzip2 files can only be
+// read.
+func (x File) Compressed() File {
+	x.compressed = true
+	return x
+}
+
+// IsCompressed returns true if transparent compression is enabled and the file extension denotes a
+// supported compression format.
+func (x File) IsCompressed() bool {
+	_, ok := x.codec()
+	return ok
+}
+
+// codec returns the compression codec to use for this file, false if its content is used as is.
+func (x File) codec() (compressionCodec, bool) {
+	if !x.compressed {
+		return compressionCodec{}, false
+	}
+	return compressionCodecFor(x)
+}
+
+// Reader opens this file for reading. The content is decompressed if transparent compression is
+// enabled.
+func (x File) Reader() (io.ReadCloser, error) {
+	f, err := x.fs.Open(x.Path())
+	if err != nil {
+		return nil, err
+	}
+	codec, ok := x.codec()
+	if !ok {
+		return f, nil
+	}
+	cr, err := codec.reader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Annotatef(err, "could not read %s", x)
+	}
+	return compressedStream{Reader: cr, compressor: cr, file: f}, nil
+}
+
+// decode returns content read from this file, decompressed if needed.
+func (x File) decode(content []byte) ([]byte, error) {
+	codec, ok := x.codec()
+	if !ok {
+		return content, nil
+	}
+	cr, err := codec.reader(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not read %s", x)
+	}
+	defer cr.Close()
+	decoded, err := io.ReadAll(cr)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not decompress %s", x)
+	}
+	return decoded, nil
+}
+
+// encode returns content to be written to this file, compressed if needed.
+func (x File) encode(content []byte) ([]byte, error) {
+	codec, ok := x.codec()
+	if !ok {
+		return content, nil
+	}
+	var b bytes.Buffer
+	cw, err := codec.writer(&b, CompressionDefault)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not write %s", x)
+	}
+	_, err = cw.Write(content)
+	closeErr := cw.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not compress %s", x)
+	}
+	return b.Bytes(), nil
+}
+
+// compressingWriter wraps f so that written content is compressed if needed.
+func (x File) compressingWriter(f afero.File) (io.WriteCloser, error) {
+	codec, ok := x.codec()
+	if !ok {
+		return f, nil
+	}
+	cw, err := codec.writer(f, CompressionDefault)
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Annotatef(err, "could not write %s", x)
+	}
+	return compressedStream{Writer: cw, compressor: cw, file: f}, nil
+}
+
+// compressedStream reads or writes through a compressor and closes it before the underlying file.
+type compressedStream struct {
+	io.Reader
+	io.Writer
+	compressor io.Closer
+	file       io.Closer
+}
+
+func (x compressedStream) Close() error {
+	err := x.compressor.Close()
+	closeErr := x.file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
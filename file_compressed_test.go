@@ -0,0 +1,68 @@
+package gofs
+
+import (
+	"compress/gzip"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestFile_Compressed(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+
+	for _, name := range []string{"/tmp/app.log", "/tmp/app.log.gz", "/tmp/app.log.zst", "/tmp/app.log.xz", "/tmp/APP.LOG.GZ"} {
+		f := FileWithFs(name, fs).Compressed()
+		a.Nil(f.SetContentString("line 1\n"), name)
+		a.Nil(f.AppendString("line 2\n"), name)
+		a.Equal("line 1\nline 2\n", f.MustContentString(), name)
+
+		w, err := f.Writer()
+		a.Nil(err, name)
+		_, err = io.WriteString(w, "written")
+		a.Nil(err, name)
+		a.Nil(w.Close(), name)
+		r, err := f.Reader()
+		a.Nil(err, name)
+		content, err := io.ReadAll(r)
+		a.Nil(err, name)
+		a.Nil(r.Close(), name)
+		a.Equal("written", string(content), name)
+
+		a.Nil(f.AtomicSetContentString("atomic"), name)
+		a.Equal("atomic", f.MustContentString(), name)
+	}
+
+	// the content is really compressed
+	f := FileWithFs("/tmp/app.log.gz", fs)
+	a.True(f.Compressed().IsCompressed())
+	a.False(f.IsCompressed())
+	a.False(FileWithFs("/tmp/app.log", fs).Compressed().IsCompressed())
+	r, err := f.Reader()
+	a.Nil(err)
+	gr, err := gzip.NewReader(r)
+	a.Nil(err)
+	content, err := io.ReadAll(gr)
+	a.Nil(err)
+	a.Nil(r.Close())
+	a.Equal("atomic", string(content))
+	a.NotEqual("atomic", f.MustContentString())
+
+	// bzip2 can only be read
+	bz2 := FileWithFs("/tmp/app.log.bz2", fs)
+	a.Nil(bz2.SetContent([]byte{
+		0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x31, 0x88,
+		0x21, 0x68, 0x00, 0x00, 0x05, 0x59, 0x00, 0x00, 0x10, 0x40, 0x00, 0x30,
+		0x00, 0x02, 0x25, 0x20, 0x00, 0x31, 0x0c, 0x08, 0x12, 0x86, 0x46, 0x89,
+		0x31, 0x90, 0x87, 0x10, 0xf1, 0x77, 0x24, 0x53, 0x85, 0x09, 0x03, 0x18,
+		0x82, 0x16, 0x80,
+	}))
+	a.Equal("line 1\nline 2\n", bz2.Compressed().MustContentString())
+	a.Error(bz2.Compressed().SetContentString("new"))
+
+	// corrupt content
+	a.Nil(f.SetContentString("not compressed"))
+	_, err = f.Compressed().Content()
+	a.Error(err)
+}
@@ -2,32 +2,159 @@ package gofs
 
 import (
 	"github.com/juju/errors"
+	"github.com/spf13/afero"
 	"io"
+	"os"
 )
 
+// CopyToDir copies this file into target keeping its filename. The copy is written to the
+// filesystem of target.
 func (x File) CopyToDir(target Dir) error {
-	return x.CopyTo(x.InDir(target))
+	return x.CopyTo(FileAtDir(target, x.Filename()))
 }
 
+// CopyTo copies the content of this file to target, replacing it if it exists. Source and target
+// may use different filesystems, the copy is always written to the filesystem of target.
 func (x File) CopyTo(target File) error {
-	// Open the source file
+	return x.copyWithOptions(target, CopyOptions{})
+}
+
+// CopyToWithOptions copies the content of this file to target like CopyTo, honoring the overwrite
+// policy and the metadata to preserve given in opts.
+func (x File) CopyToWithOptions(target File, opts CopyOptions) error {
+	return x.copyWithOptions(target, opts)
+}
+
+func (x File) MustCopyTo(target File) File {
+	err := x.CopyTo(target)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// copyWithOptions copies the content of this file to target, honoring the overwrite policy and the
+// metadata to preserve. Source and target may live on different filesystems.
+func (x File) copyWithOptions(target File, opts CopyOptions) error {
+	info, err := x.fs.Stat(x.path)
+	if err != nil {
+		return errors.Annotatef(err, "Error reading source file")
+	}
+
+	copyNeeded, err := checkOverwrite(target.fs, target.Path(), info, opts.Overwrite)
+	if err != nil || !copyNeeded {
+		return err
+	}
+	// replace a symlink at target instead of writing to the file it points to
+	if existing, err := lstat(target.fs, target.Path()); err == nil && existing.Mode()&os.ModeSymlink != 0 {
+		err = target.fs.Remove(target.Path())
+		if err != nil {
+			return errors.Annotatef(err, "Error removing symlink at destination")
+		}
+	}
+
+	perm := target.createPermissions
+	if opts.PreserveMode {
+		perm = info.Mode().Perm()
+	}
+
 	src, err := x.fs.Open(x.path)
 	if err != nil {
 		return errors.Annotatef(err, "Error opening source file")
 	}
 	defer src.Close()
 
-	// Create the destination file
-	dest, err := x.fs.Create(target.Path())
+	dest, err := target.fs.OpenFile(target.Path(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
 		return errors.Annotatef(err, "Error creating destination file")
 	}
-	defer dest.Close()
-
-	// Copy the contents from source to destination
 	_, err = io.Copy(dest, src)
+	closeErr := dest.Close()
 	if err != nil {
 		return errors.Annotatef(err, "Error copying file")
 	}
+	if closeErr != nil {
+		return errors.Annotatef(closeErr, "Error closing destination file")
+	}
+
+	return applyMetadata(target.fs, target.Path(), info, opts)
+}
+
+// checkOverwrite returns if a copy to targetPath should happen according to policy.
+func checkOverwrite(targetFs afero.Fs, targetPath string, source os.FileInfo, policy OverwritePolicy) (bool, error) {
+	existing, err := lstat(targetFs, targetPath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch policy {
+	case OverwriteNever:
+		return false, nil
+	case OverwriteError:
+		return false, NewFileExistsError(targetPath)
+	case OverwriteIfNewer:
+		return source.ModTime().After(existing.ModTime()), nil
+	default:
+		return true, nil
+	}
+}
+
+// applyMetadata sets mode and modification time of path as requested by opts.
+func applyMetadata(fs afero.Fs, path string, info os.FileInfo, opts CopyOptions) error {
+	if opts.PreserveMode {
+		err := fs.Chmod(path, info.Mode().Perm())
+		if err != nil {
+			return errors.Annotatef(err, "Error setting mode of %s", path)
+		}
+	}
+	if opts.PreserveTimes {
+		err := fs.Chtimes(path, info.ModTime(), info.ModTime())
+		if err != nil {
+			return errors.Annotatef(err, "Error setting times of %s", path)
+		}
+	}
 	return nil
 }
+
+// copySymlink recreates the symlink at sourcePath as targetPath.
+func copySymlink(sourceFs afero.Fs, sourcePath string, targetFs afero.Fs, targetPath string, opts CopyOptions) error {
+	reader, ok := sourceFs.(afero.LinkReader)
+	if !ok {
+		return errors.NotSupportedf("reading symlinks on %T", sourceFs)
+	}
+	linker, ok := targetFs.(afero.Linker)
+	if !ok {
+		return errors.NotSupportedf("creating symlinks on %T", targetFs)
+	}
+
+	info, err := lstat(sourceFs, sourcePath)
+	if err != nil {
+		return err
+	}
+	copyNeeded, err := checkOverwrite(targetFs, targetPath, info, opts.Overwrite)
+	if err != nil || !copyNeeded {
+		return err
+	}
+
+	link, err := reader.ReadlinkIfPossible(sourcePath)
+	if err != nil {
+		return errors.Annotatef(err, "Error reading symlink %s", sourcePath)
+	}
+	err = targetFs.Remove(targetPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return linker.SymlinkIfPossible(link, targetPath)
+}
+
+// lstat stats path without following a final symlink where the filesystem supports it.
+func lstat(fs afero.Fs, path string) (os.FileInfo, error) {
+	if lstater, ok := fs.(afero.Lstater); ok {
+		info, _, err := lstater.LstatIfPossible(path)
+		return info, err
+	}
+	return fs.Stat(path)
+}
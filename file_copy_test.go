@@ -0,0 +1,50 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile_CopyTo_CrossFs(t *testing.T) {
+	a := assert.New(t)
+	memFs := afero.NewMemMapFs()
+	otherMemFs := afero.NewMemMapFs()
+
+	src := FileWithFs("/data/source.txt", memFs)
+	a.Nil(src.SetContentString("staged"))
+
+	// memory to memory
+	target := FileWithFs("/data/target.txt", otherMemFs)
+	a.Nil(src.CopyTo(target))
+	a.Equal("staged", target.MustContentString())
+	a.True(target.WithFs(memFs).NotExists())
+
+	// memory to disk
+	disk := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.CopyToDir(disk))
+	a.Equal("staged", FileAt(disk.MustFileAt("source.txt").Path()).MustContentString())
+
+	// disk to memory, whole tree
+	a.Nil(disk.MustFileAt("sub/nested.txt").MustEnsureDir(0750).SetContentString("nested"))
+	memDir := DirWithFs("/fixtures", memFs)
+	a.Nil(disk.CopyTo(memDir, CopyOptions{}))
+	a.Equal("nested", memDir.MustFileAt("sub/nested.txt").MustContentString())
+}
+
+func TestFile_CopyTo_ReplacesSymlink(t *testing.T) {
+	a := assert.New(t)
+	dir := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(dir.MustFileAt("victim.txt").SetContentString("victim"))
+	a.Nil(dir.MustFileAt("source.txt").SetContentString("source"))
+	a.Nil(os.Symlink("victim.txt", filepath.Join(dir.Path(), "target.txt")))
+
+	a.Nil(dir.MustFileAt("source.txt").CopyTo(dir.MustFileAt("target.txt")))
+	info, err := os.Lstat(dir.MustFileAt("target.txt").Path())
+	a.Nil(err)
+	a.Zero(info.Mode() & os.ModeSymlink)
+	a.Equal("source", dir.MustFileAt("target.txt").MustContentString())
+	a.Equal("victim", dir.MustFileAt("victim.txt").MustContentString())
+}
@@ -0,0 +1,21 @@
+package gofs
+
+import "fmt"
+
+type FileExistsError struct {
+	path string
+}
+
+func NewFileExistsError(path string) *FileExistsError {
+	return &FileExistsError{
+		path: path,
+	}
+}
+
+func (x FileExistsError) Error() string {
+	return fmt.Sprintf("file was expected not to exist, but it did: %s", x.path)
+}
+
+func (x FileExistsError) Unwrap() error {
+	return ErrExist
+}
@@ -1,7 +1,6 @@
 package gofs
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
 )
@@ -16,10 +15,16 @@ func (x File) HasExtension(fileExtension FileExtension) bool {
 }
 
 func (x File) AssertExtension(fileExtension FileExtension) File {
+	return mustCheck(x, x.CheckExtension(fileExtension))
+}
+
+// CheckExtension returns an AssertionError wrapping ErrExtensionMismatch if this file does not have
+// the given extension.
+func (x File) CheckExtension(fileExtension FileExtension) error {
 	if !x.HasExtension(fileExtension) {
-		panic(fmt.Errorf("file %s should have had file extension %s", x, fileExtension))
+		return NewAssertionError(ErrExtensionMismatch, x.path, "file %s should have had file extension %s", x, fileExtension)
 	}
-	return x
+	return nil
 }
 
 func (x File) WithExtension(fileExtension FileExtension) File {
@@ -32,4 +32,19 @@ var (
 	ExtPng = FileExtensionFrom("png")
 	ExtLog = FileExtensionFrom("log")
 	ExtZip = FileExtensionFrom("zip")
+
+	ExtJson = FileExtensionFrom("json")
+	ExtYaml = FileExtensionFrom("yaml")
+	ExtYml  = FileExtensionFrom("yml")
+	ExtToml = FileExtensionFrom("toml")
+
+	ExtTar    = FileExtensionFrom("tar")
+	ExtTarGz  = FileExtensionFrom("tar.gz")
+	ExtTgz    = FileExtensionFrom("tgz")
+	ExtTarZst = FileExtensionFrom("tar.zst")
+	ExtTarXz  = FileExtensionFrom("tar.xz")
+	ExtGz     = FileExtensionFrom("gz")
+	ExtZst    = FileExtensionFrom("zst")
+	ExtXz     = FileExtensionFrom("xz")
+	ExtBz2    = FileExtensionFrom("bz2")
 )
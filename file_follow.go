@@ -0,0 +1,208 @@
+package gofs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"os"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/afero/mem"
+)
+
+// FollowOptions configures following a file.
+type FollowOptions struct {
+	// FromStart yields the lines already in the file first. By default, only lines appended after
+	// following started are yielded.
+	FromStart bool
+	// PollInterval is the interval to check the file for changes. On the OS filesystem, changes are
+	// noticed earlier through inotify. Defaults to 250ms.
+	PollInterval time.Duration
+}
+
+// Follow returns an iterator over the lines appended to this file, like "tail -F". It waits for
+// the file to be created if it does not exist. Truncation and rotation (the path being replaced by
+// a new file) are detected and the file is reopened automatically, rotation detection needs file IDs
+// like on unix systems or the in-memory filesystem. Lines are only yielded once they
+// are complete, line endings are stripped. Iteration ends when ctx is done or an error is yielded.
+func (x File) Follow(ctx context.Context) iter.Seq2[string, error] {
+	return x.FollowWithOptions(ctx, FollowOptions{})
+}
+
+// FollowWithOptions returns an iterator over the lines appended to this file like Follow.
+func (x File) FollowWithOptions(ctx context.Context, opts FollowOptions) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		notifier := newChangeNotifier(x.fs, opts.PollInterval)
+		defer notifier.close()
+		// watching the dir notices both writes to the file and it being replaced
+		notifier.add(x.Dir().Path())
+
+		follower := &fileFollower{file: x}
+		defer follower.close()
+		err := follower.open(!opts.FromStart)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for {
+			ok, err := follower.readLines(yield)
+			if !ok {
+				return
+			}
+			if err == nil {
+				ok, err = follower.checkReplaced(yield)
+				if !ok {
+					return
+				}
+			}
+			if err != nil {
+				yield("", errors.Annotatef(err, "could not follow %s", x))
+				return
+			}
+			if !notifier.wait(ctx) {
+				return
+			}
+		}
+	}
+}
+
+// fileFollower reads the lines appended to a file.
+type fileFollower struct {
+	file File
+	// f is the currently followed file, nil while the file does not exist.
+	f      afero.File
+	offset int64
+	// partial is the beginning of a line that is not complete yet.
+	partial []byte
+	buf     []byte
+}
+
+// open opens the file if it exists. If atEnd is true, existing content is skipped.
+func (x *fileFollower) open(atEnd bool) error {
+	f, err := x.file.fs.Open(x.file.Path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	x.f = f
+	x.offset = 0
+	x.partial = x.partial[:0]
+	if atEnd {
+		x.offset, err = f.Seek(0, io.SeekEnd)
+	}
+	return err
+}
+
+// readLines passes all complete lines available to yield. false is returned if yield asked to stop.
+func (x *fileFollower) readLines(yield func(string, error) bool) (bool, error) {
+	if x.f == nil {
+		return true, nil
+	}
+	if x.buf == nil {
+		x.buf = make([]byte, 32*1024)
+	}
+	for {
+		n, err := x.f.Read(x.buf)
+		x.offset += int64(n)
+		data := x.buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i == -1 {
+				x.partial = append(x.partial, data...)
+				break
+			}
+			line := append(x.partial, data[:i]...)
+			x.partial = x.partial[:0]
+			data = data[i+1:]
+			if !yield(string(bytes.TrimSuffix(line, []byte{'\r'})), nil) {
+				return false, nil
+			}
+		}
+		// the in-memory filesystem reports reading beyond the end of truncated files as unexpected
+		// EOF, truncation is handled afterwards
+		if err == io.EOF || err == io.ErrUnexpectedEOF || (err == nil && n == 0) {
+			return true, nil
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+}
+
+// checkReplaced reopens the file if it was truncated, replaced or created. Before switching to a
+// new file, the rest of the old file is passed to yield, including an incomplete last line. false is
+// returned if yield asked to stop.
+func (x *fileFollower) checkReplaced(yield func(string, error) bool) (bool, error) {
+	info, err := x.file.fs.Stat(x.file.Path())
+	if os.IsNotExist(err) {
+		// rotation in progress, keep the old file until a new one appears
+		return true, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	if x.f == nil {
+		return true, x.open(false)
+	}
+
+	current, err := x.f.Stat()
+	if err != nil {
+		return true, err
+	}
+	if !sameFile(current, info) {
+		// lines might have been written to the old file since it was read last
+		ok, err := x.readLines(yield)
+		if !ok || err != nil {
+			return ok, err
+		}
+		if len(x.partial) > 0 && !yield(string(bytes.TrimSuffix(x.partial, []byte{'\r'})), nil) {
+			return false, nil
+		}
+		x.close()
+		return true, x.open(false)
+	}
+	if info.Size() < x.offset {
+		// truncated
+		x.partial = x.partial[:0]
+		x.offset, err = x.f.Seek(0, io.SeekStart)
+		return true, err
+	}
+	return true, nil
+}
+
+func (x *fileFollower) close() {
+	if x.f == nil {
+		return
+	}
+	_ = x.f.Close()
+	x.f = nil
+}
+
+// sameFile returns true if a and b describe the same file. If the filesystem does not provide file
+// IDs, files are always considered the same (see fileIdentity).
+func sameFile(a, b os.FileInfo) bool {
+	same, known := fileIdentity(a, b)
+	return same || !known
+}
+
+// fileIdentity returns if a and b describe the same file. known is false if the filesystem does not
+// provide file IDs, which are available for the OS filesystem on unix systems and the in-memory
+// filesystem.
+func fileIdentity(a, b os.FileInfo) (same bool, known bool) {
+	aID, aOk := fileIDFromInfo(a)
+	bID, bOk := fileIDFromInfo(b)
+	if aOk && bOk {
+		return aID == bID, true
+	}
+	if aMem, ok := a.(*mem.FileInfo); ok {
+		bMem, ok := b.(*mem.FileInfo)
+		return ok && aMem.FileData == bMem.FileData, ok
+	}
+	return false, false
+}
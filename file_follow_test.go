@@ -0,0 +1,119 @@
+package gofs
+
+import (
+	"context"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// followLines follows f in the background and sends the yielded lines and errors to the returned
+// channel.
+func followLines(t *testing.T, f File, opts FollowOptions) <-chan string {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	lines := make(chan string, 100)
+	started := make(chan struct{})
+	go func() {
+		defer close(lines)
+		close(started)
+		for line, err := range f.FollowWithOptions(ctx, opts) {
+			if err != nil {
+				lines <- "error: " + err.Error()
+				return
+			}
+			lines <- line
+		}
+	}()
+	<-started
+	return lines
+}
+
+func receiveLines(t *testing.T, lines <-chan string, n int) []string {
+	t.Helper()
+	result := make([]string, 0, n)
+	timeout := time.After(5 * time.Second)
+	for len(result) < n {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("follow ended after %v", result)
+			}
+			result = append(result, line)
+		case <-timeout:
+			t.Fatalf("timeout after %v", result)
+		}
+	}
+	return result
+}
+
+func testFollow(t *testing.T, fs afero.Fs, dir string) {
+	a := assert.New(t)
+	f := FileWithFs(dir+"/app.log", fs)
+	a.Nil(f.SetContentString("old\n"))
+
+	lines := followLines(t, f, FollowOptions{PollInterval: 10 * time.Millisecond})
+	// give the follower time to open the file before appending
+	time.Sleep(50 * time.Millisecond)
+	a.Nil(f.AppendString("first\r\nsec"))
+	a.Nil(f.AppendString("ond\n"))
+	a.Equal([]string{"first", "second"}, receiveLines(t, lines, 2))
+
+	// truncation
+	a.Nil(f.SetContentString(""))
+	time.Sleep(50 * time.Millisecond)
+	a.Nil(f.AppendString("after truncation\n"))
+	a.Equal([]string{"after truncation"}, receiveLines(t, lines, 1))
+
+	// rotation
+	a.Nil(fs.Rename(f.Path(), dir+"/app.log.1"))
+	time.Sleep(50 * time.Millisecond)
+	a.Nil(f.SetContentString("rotated\n"))
+	a.Equal([]string{"rotated"}, receiveLines(t, lines, 1))
+
+	// lines appended right before rotation are not lost, including an incomplete last line
+	a.Nil(f.AppendString("last\nincomplete"))
+	a.Nil(fs.Rename(f.Path(), dir+"/app.log.2"))
+	a.Nil(f.SetContentString("new\n"))
+	a.Equal([]string{"last", "incomplete", "new"}, receiveLines(t, lines, 3))
+}
+
+func TestFile_Follow(t *testing.T) {
+	testFollow(t, afero.NewMemMapFs(), "/tmp")
+}
+
+func TestFile_Follow_OsFs(t *testing.T) {
+	testFollow(t, afero.NewOsFs(), t.TempDir())
+}
+
+func TestFile_Follow_FromStart(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	f := FileWithFs("/tmp/later.log", fs)
+
+	lines := followLines(t, f, FollowOptions{FromStart: true, PollInterval: 10 * time.Millisecond})
+	time.Sleep(50 * time.Millisecond)
+	a.Nil(f.SetContentString("created\n"))
+	a.Equal([]string{"created"}, receiveLines(t, lines, 1))
+
+	existing := FileWithFs("/tmp/existing.log", fs)
+	a.Nil(existing.SetContentString("a\nb\n"))
+	lines = followLines(t, existing, FollowOptions{FromStart: true, PollInterval: 10 * time.Millisecond})
+	a.Equal([]string{"a", "b"}, receiveLines(t, lines, 2))
+}
+
+func TestFile_Follow_Cancel(t *testing.T) {
+	a := assert.New(t)
+	f := FileWithFs("/tmp/app.log", afero.NewMemMapFs())
+	a.Nil(f.SetContentString("old\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	count := 0
+	for range f.Follow(ctx) {
+		count++
+	}
+	a.Equal(0, count)
+}
@@ -1,26 +1,18 @@
 package gofs
 
 import (
-	"crypto/md5"
-	"fmt"
+	"github.com/juju/errors"
 	"github.com/spf13/afero"
 	"hash"
 	"io"
 )
 
 func (x File) Md5Hash() (string, error) {
-	var md5hash hash.Hash
-	err := x.WithFileReadOnly(func(f afero.File) error {
-		md5hash = md5.New()
-		_, err := io.Copy(md5hash, f)
-		return err
-	})
-
+	digest, err := x.Hash(HashMd5)
 	if err != nil {
 		return "", err
 	}
-
-	return fmt.Sprintf("%x", md5hash.Sum(nil)), nil
+	return digest.Hex(), nil
 }
 
 func (x File) MustMd5Hash() string {
@@ -32,8 +24,80 @@ func (x File) MustMd5Hash() string {
 }
 
 func (x File) AssertMd5Hash(hash string) File {
-	if x.MustMd5Hash() != hash {
-		panic(fmt.Errorf("file %s should have had md5 hash %s", x, hash))
+	return mustCheck(x, x.CheckMd5Hash(hash))
+}
+
+// CheckMd5Hash returns an AssertionError wrapping ErrHashMismatch if the content of this file does not
+// have the given md5 hash.
+func (x File) CheckMd5Hash(hash string) error {
+	md5Hash, err := x.Md5Hash()
+	if err != nil {
+		return err
+	}
+	if md5Hash != hash {
+		return NewAssertionError(ErrHashMismatch, x.path, "file %s should have had md5 hash %s", x, hash)
+	}
+	return nil
+}
+
+// Hash returns the digest of the content of this file using algorithm.
+func (x File) Hash(algorithm HashAlgorithm) (Digest, error) {
+	digests, err := x.Hashes(algorithm)
+	if err != nil {
+		return Digest{}, err
+	}
+	return digests[0], nil
+}
+
+func (x File) MustHash(algorithm HashAlgorithm) Digest {
+	digest, err := x.Hash(algorithm)
+	if err != nil {
+		panic(err)
+	}
+	return digest
+}
+
+// Hashes computes the digests of the content of this file for all given algorithms, reading the
+// file only once. The digests are returned in the order of the algorithms.
+func (x File) Hashes(algorithms ...HashAlgorithm) ([]Digest, error) {
+	hashes := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, algorithm := range algorithms {
+		if algorithm.factory == nil {
+			return nil, errors.NotValidf("hash algorithm %q", algorithm.name)
+		}
+		hashes[i] = algorithm.New()
+		writers[i] = hashes[i]
+	}
+
+	err := x.WithFileReadOnly(func(f afero.File) error {
+		_, err := io.Copy(io.MultiWriter(writers...), f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]Digest, len(algorithms))
+	for i, algorithm := range algorithms {
+		digests[i] = NewDigest(algorithm, hashes[i].Sum(nil))
+	}
+	return digests, nil
+}
+
+func (x File) AssertHash(expected Digest) File {
+	return mustCheck(x, x.CheckHash(expected))
+}
+
+// CheckHash returns an AssertionError wrapping ErrHashMismatch if the content of this file does not
+// have the expected digest.
+func (x File) CheckHash(expected Digest) error {
+	digest, err := x.Hash(expected.Algorithm())
+	if err != nil {
+		return err
+	}
+	if !digest.Equals(expected) {
+		return NewAssertionError(ErrHashMismatch, x.path, "file %s should have had %s hash %s", x, expected.Algorithm(), expected)
 	}
-	return x
+	return nil
 }
@@ -0,0 +1,68 @@
+package gofs
+
+import (
+	"crypto/sha256"
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"hash"
+	"testing"
+)
+
+func TestFile_Hash(t *testing.T) {
+	a := assert.New(t)
+	f := FileWithFs("/tmp/hash.txt", afero.NewMemMapFs())
+	a.Nil(f.SetContentString("abc"))
+
+	tests := []struct {
+		algorithm HashAlgorithm
+		want      string
+	}{
+		{HashMd5, "900150983cd24fb0d6963f7d28e17f72"},
+		{HashSha1, "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{HashSha256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashBlake3, "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{HashCrc32, "352441c2"},
+	}
+	for _, tt := range tests {
+		digest, err := f.Hash(tt.algorithm)
+		a.Nil(err)
+		a.Equal(tt.want, digest.Hex(), tt.algorithm.Name())
+	}
+
+	digests, err := f.Hashes(HashSha256, HashSha512, HashBlake2b256)
+	a.Nil(err)
+	a.Len(digests, 3)
+	a.Equal("ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=", digests[0].Base64())
+	a.Equal(64, len(digests[1].Bytes()))
+	a.Equal(HashBlake2b256.Name(), digests[2].Algorithm().Name())
+
+	expected, err := ParseHexDigest(HashSha256, tests[2].want)
+	a.Nil(err)
+	a.True(expected.Equals(digests[0]))
+	a.Nil(f.CheckHash(expected))
+	a.Nil(f.SetContentString("changed"))
+	a.ErrorIs(f.CheckHash(expected), ErrHashMismatch)
+
+	_, err = ParseHexDigest(HashSha256, "abcd")
+	a.NotNil(err)
+}
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	a := assert.New(t)
+
+	custom := RegisterHashAlgorithm("Custom-SHA224", func() hash.Hash { return sha256.New224() })
+	found, err := HashAlgorithmByName("custom-sha224")
+	a.Nil(err)
+	a.Equal(custom.Name(), found.Name())
+	a.Equal(28, found.Size())
+
+	unknown, err := HashAlgorithmByName("unknown")
+	a.NotNil(err)
+
+	// the zero value returned with the error is rejected instead of panicking
+	f := FileWithFs("/tmp/hash.txt", afero.NewMemMapFs())
+	a.Nil(f.SetContentString("abc"))
+	_, err = f.Hash(unknown)
+	a.True(errors.IsNotValid(err))
+}
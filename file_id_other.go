@@ -0,0 +1,18 @@
+//go:build !unix
+
+package gofs
+
+import (
+	"os"
+)
+
+// fileID identifies a file independently of its path, hardlinks share the same fileID.
+type fileID struct {
+	device uint64
+	inode  uint64
+}
+
+// fileIDFromInfo returns the fileID of info if the filesystem provides one.
+func fileIDFromInfo(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package gofs
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID identifies a file independently of its path, hardlinks share the same fileID.
+type fileID struct {
+	device uint64
+	inode  uint64
+}
+
+// fileIDFromInfo returns the fileID of info if the filesystem provides one.
+func fileIDFromInfo(info os.FileInfo) (fileID, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+	return fileID{device: uint64(stat.Dev), inode: uint64(stat.Ino)}, true
+}
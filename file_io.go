@@ -1,7 +1,6 @@
 package gofs
 
 import (
-	"fmt"
 	"github.com/juju/errors"
 	"github.com/spf13/afero"
 	"io"
@@ -17,37 +16,70 @@ func (x File) IsReadable() bool {
 }
 
 func (x File) AssertReadable() File {
+	return mustCheck(x, x.CheckReadable())
+}
+
+// CheckReadable returns an AssertionError wrapping ErrNotReadable if this file can not be opened for
+// reading.
+func (x File) CheckReadable() error {
 	if !x.IsReadable() {
-		panic(fmt.Errorf("file %s should have been readable", x))
+		return NewAssertionError(ErrNotReadable, x.path, "file %s should have been readable", x)
 	}
-	return x
+	return nil
 }
 
 func (x File) IsWritable() bool {
+	writable, err := x.IsWritableE()
+	if err != nil {
+		panic(err)
+	}
+	return writable
+}
+
+// IsWritableE returns if this file can be opened for writing. If the file did not exist, it is
+// created for the check and removed again. An error is returned if that removal fails.
+func (x File) IsWritableE() (bool, error) {
 	filePath := x.Path()
 	existed := x.Exists()
-	f, err := os.OpenFile(filePath, os.O_RDWR+os.O_CREATE, x.createPermissions)
-	if err == nil {
-		_ = f.Close()
-		if !existed {
-			err = os.Remove(filePath)
-			if err != nil {
-				panic(err)
-			}
+	f, err := x.fs.OpenFile(filePath, os.O_RDWR|os.O_CREATE, x.createPermissions)
+	if err != nil {
+		return false, nil
+	}
+	_ = f.Close()
+	if !existed {
+		err = x.fs.Remove(filePath)
+		if err != nil {
+			return true, errors.Annotatef(err, "could not remove %s after checking it is writable", x)
 		}
 	}
-	return err == nil
+	return true, nil
 }
 
 func (x File) AssertWritable() File {
-	if !x.IsWritable() {
-		panic(fmt.Errorf("file %s should have been writable", x))
+	return mustCheck(x, x.CheckWritable())
+}
+
+// CheckWritable returns an AssertionError wrapping ErrNotWritable if this file can not be opened for
+// writing.
+func (x File) CheckWritable() error {
+	writable, err := x.IsWritableE()
+	if err != nil {
+		return err
 	}
-	return x
+	if !writable {
+		return NewAssertionError(ErrNotWritable, x.path, "file %s should have been writable", x)
+	}
+	return nil
 }
 
+// Content returns the content of this file, decompressed if transparent compression is enabled
+// (see Compressed).
 func (x File) Content() ([]byte, error) {
-	return afero.ReadFile(x.fs, x.Path())
+	content, err := afero.ReadFile(x.fs, x.Path())
+	if err != nil {
+		return nil, err
+	}
+	return x.decode(content)
 }
 
 func (x File) MustContent() []byte {
@@ -59,7 +91,7 @@ func (x File) MustContent() []byte {
 }
 
 func (x File) ContentString() (string, error) {
-	content, err := afero.ReadFile(x.fs, x.Path())
+	content, err := x.Content()
 	return string(content), err
 }
 
@@ -71,7 +103,14 @@ func (x File) MustContentString() string {
 	return string(content)
 }
 
+// Append adds newContent to the end of this file. If transparent compression is enabled, it is
+// appended as a separate compressed stream, which decompressors read as continuation of the content.
 func (x File) Append(newContent []byte) error {
+	newContent, err := x.encode(newContent)
+	if err != nil {
+		return err
+	}
+
 	f, err := x.fs.OpenFile(x.Path(), os.O_RDWR, x.createPermissions)
 	if err != nil {
 		return err
@@ -95,7 +134,13 @@ func (x File) AppendStringln(newContent string) error {
 	return x.AppendString(newContent + "\n")
 }
 
+// SetContent replaces the content of this file, compressing it if transparent compression is
+// enabled (see Compressed).
 func (x File) SetContent(newContent []byte) error {
+	newContent, err := x.encode(newContent)
+	if err != nil {
+		return err
+	}
 	return afero.WriteFile(x.fs, x.Path(), newContent, x.createPermissions)
 }
 
@@ -130,6 +175,12 @@ func (x File) MustRemove() File {
 	return x
 }
 
+// Writer truncates this file and opens it for writing. Written content is compressed if transparent
+// compression is enabled, closing the writer finishes the compressed stream.
 func (x File) Writer() (io.WriteCloser, error) {
-	return x.fs.Create(x.path)
+	f, err := x.fs.Create(x.path)
+	if err != nil {
+		return nil, err
+	}
+	return x.compressingWriter(f)
 }
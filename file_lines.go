@@ -0,0 +1,202 @@
+package gofs
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"iter"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// tailChunkSize is the number of bytes Tail reads at once while seeking backwards.
+const tailChunkSize = 4096
+
+// Lines returns an iterator over the lines of this file without loading it into memory. Line
+// endings ("\n" or "\r\n") are stripped, lines can be of any length. A read error is yielded as the
+// last element. Compressed files are decompressed if transparent compression is enabled (see
+// Compressed).
+func (x File) Lines() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		r, err := x.Reader()
+		if err != nil {
+			yield("", err)
+			return
+		}
+		defer r.Close()
+		readLines(r, yield)
+	}
+}
+
+// Head returns the first n lines of this file.
+func (x File) Head(n int) ([]string, error) {
+	// n is not used as capacity, it might be much larger than the file
+	result := make([]string, 0)
+	if n <= 0 {
+		return result, nil
+	}
+	for line, err := range x.Lines() {
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, line)
+		if len(result) == n {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Tail returns the last n lines of this file. The file is read backwards from its end, so only the
+// requested lines are read. Compressed files can not be read backwards, they are streamed instead.
+func (x File) Tail(n int) ([]string, error) {
+	if n <= 0 {
+		return make([]string, 0), nil
+	}
+	if x.IsCompressed() {
+		return x.streamTail(n)
+	}
+
+	f, err := x.fs.Open(x.Path())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := tailOffset(f, info.Size(), n)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not read %s", x)
+	}
+	result := make([]string, 0)
+	var readErr error
+	readLines(io.NewSectionReader(f, start, info.Size()-start), func(line string, err error) bool {
+		if err != nil {
+			readErr = err
+			return false
+		}
+		result = append(result, line)
+		return true
+	})
+	if readErr != nil {
+		return nil, errors.Annotatef(readErr, "could not read %s", x)
+	}
+	return result, nil
+}
+
+// LineCount returns the number of lines of this file. A last line without line break is counted.
+func (x File) LineCount() (int, error) {
+	r, err := x.Reader()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	count := 0
+	endsWithLineBreak := true
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			count += bytes.Count(buf[:n], []byte{'\n'})
+			endsWithLineBreak = buf[n-1] == '\n'
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Annotatef(err, "could not read %s", x)
+		}
+	}
+	if !endsWithLineBreak {
+		count++
+	}
+	return count, nil
+}
+
+// streamTail returns the last n lines by reading all lines and keeping the last n in a ring buffer.
+// The ring buffer grows with the lines read up to n.
+func (x File) streamTail(n int) ([]string, error) {
+	ring := make([]string, 0)
+	count := 0
+	for line, err := range x.Lines() {
+		if err != nil {
+			return nil, err
+		}
+		if len(ring) < n {
+			ring = append(ring, line)
+		} else {
+			ring[count%n] = line
+		}
+		count++
+	}
+	if count <= n {
+		return ring[:count], nil
+	}
+	start := count % n
+	return append(ring[start:], ring[:start]...), nil
+}
+
+// tailOffset returns the offset of the first of the last n lines in r, which has the given size.
+func tailOffset(r io.ReaderAt, size int64, n int) (int64, error) {
+	end := size
+	// a line break at the very end terminates the last line and does not start a new one
+	if size > 0 {
+		last := make([]byte, 1)
+		_, err := r.ReadAt(last, size-1)
+		if err != nil {
+			return 0, err
+		}
+		if last[0] == '\n' {
+			end--
+		}
+	}
+
+	found := 0
+	buf := make([]byte, tailChunkSize)
+	for end > 0 {
+		chunkStart := max(end-tailChunkSize, 0)
+		chunk := buf[:end-chunkStart]
+		_, err := r.ReadAt(chunk, chunkStart)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' {
+				continue
+			}
+			found++
+			if found == n {
+				return chunkStart + int64(i) + 1, nil
+			}
+		}
+		end = chunkStart
+	}
+	return 0, nil
+}
+
+// readLines passes the lines read from r to yield until it returns false. Line endings are
+// stripped, a read error is passed as last call.
+func readLines(r io.Reader, yield func(string, error) bool) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if !yield(line, nil) {
+				return
+			}
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield("", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package gofs
+
+import (
+	"fmt"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFile_Lines(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	longLine := strings.Repeat("x", 200*1024)
+
+	f := FileWithFs("/tmp/app.log", fs)
+	a.Nil(f.SetContentString("first\r\nsecond\n\n" + longLine + "\nlast"))
+	lines := make([]string, 0)
+	for line, err := range f.Lines() {
+		a.Nil(err)
+		lines = append(lines, line)
+	}
+	a.Equal([]string{"first", "second", "", longLine, "last"}, lines)
+
+	// stop early
+	for line := range f.Lines() {
+		a.Equal("first", line)
+		break
+	}
+
+	for _, err := range FileWithFs("/tmp/missing.log", fs).Lines() {
+		a.ErrorIs(err, ErrNotExist)
+	}
+}
+
+func TestFile_HeadTail(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+
+	var b strings.Builder
+	for i := 1; i <= 10000; i++ {
+		b.WriteString(fmt.Sprintf("line %d\r\n", i))
+	}
+	for _, f := range []File{FileWithFs("/tmp/app.log", fs), FileWithFs("/tmp/app.log.gz", fs).Compressed()} {
+		a.Nil(f.SetContentString(b.String()))
+
+		head, err := f.Head(3)
+		a.Nil(err)
+		a.Equal([]string{"line 1", "line 2", "line 3"}, head)
+		tail, err := f.Tail(3)
+		a.Nil(err)
+		a.Equal([]string{"line 9998", "line 9999", "line 10000"}, tail)
+		tail, err = f.Tail(2000)
+		a.Nil(err)
+		a.Len(tail, 2000)
+		a.Equal("line 8001", tail[0])
+		count, err := f.LineCount()
+		a.Nil(err)
+		a.Equal(10000, count)
+
+		// n larger than the file does not allocate n lines
+		head, err = f.Head(math.MaxInt)
+		a.Nil(err)
+		a.Len(head, 10000)
+		tail, err = f.Tail(math.MaxInt)
+		a.Nil(err)
+		a.Len(tail, 10000)
+		a.Equal("line 1", tail[0])
+	}
+
+	f := FileWithFs("/tmp/short.log", fs)
+	for _, test := range []struct {
+		content string
+		tail    []string
+		count   int
+	}{
+		{"", []string{}, 0},
+		{"\n", []string{""}, 1},
+		{"a", []string{"a"}, 1},
+		{"a\nb", []string{"a", "b"}, 2},
+		{"a\nb\n", []string{"a", "b"}, 2},
+		{"a\nb\nc\n", []string{"b", "c"}, 3},
+		{"\n\n\n", []string{"", ""}, 3},
+	} {
+		a.Nil(f.SetContentString(test.content))
+		tail, err := f.Tail(2)
+		a.Nil(err)
+		a.Equal(test.tail, tail, "%q", test.content)
+		count, err := f.LineCount()
+		a.Nil(err)
+		a.Equal(test.count, count, "%q", test.content)
+	}
+
+	head, err := f.Head(0)
+	a.Nil(err)
+	a.Empty(head)
+	_, err = FileWithFs("/tmp/missing.log", fs).Tail(1)
+	a.ErrorIs(err, ErrNotExist)
+}
@@ -0,0 +1,116 @@
+package gofs
+
+import (
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// FileLock is an acquired advisory lock on a File. Release it with Unlock.
+type FileLock struct {
+	file   File
+	once   sync.Once
+	unlock func() error
+}
+
+// File returns the locked file.
+func (x *FileLock) File() File {
+	return x.file
+}
+
+// Unlock releases the lock. Calling it more than once has no effect.
+func (x *FileLock) Unlock() error {
+	var err error
+	x.once.Do(func() {
+		err = x.unlock()
+	})
+	return err
+}
+
+// Lock acquires an exclusive advisory lock on this file, waiting until it is available.
+//
+// On the OS filesystem, the lock is held with flock on a lock file next to this file, named like it
+// with an additional ".lock" extension, so it works across processes and is kept when the file is
+// replaced, e.g. by AtomicSetContent. The lock file is not removed again. Other filesystems and
+// systems without flock use a lock table inside of this process.
+func (x File) Lock() (*FileLock, error) {
+	return x.lock(true, true)
+}
+
+// RLock acquires a shared advisory lock on this file, waiting until it is available. Multiple
+// shared locks can be held at the same time, but none while an exclusive lock is held. See Lock for
+// how locks are implemented.
+func (x File) RLock() (*FileLock, error) {
+	return x.lock(false, true)
+}
+
+// TryLock acquires an exclusive advisory lock on this file like Lock, but does not wait. If the lock
+// is held by someone else, an AssertionError wrapping ErrLocked is returned.
+func (x File) TryLock() (*FileLock, error) {
+	return x.lock(true, false)
+}
+
+// WithLock calls fn while holding an exclusive lock on this file.
+func (x File) WithLock(fn func() error) error {
+	lock, err := x.Lock()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	unlockErr := lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return unlockErr
+}
+
+// lockPath returns the path of the lock file used on the OS filesystem.
+func (x File) lockPath() string {
+	return x.Path() + ".lock"
+}
+
+func (x File) lock(exclusive, blocking bool) (*FileLock, error) {
+	if _, isOs := x.fs.(*afero.OsFs); isOs {
+		return x.osLock(exclusive, blocking)
+	}
+	return x.tableLock(exclusive, blocking)
+}
+
+func (x File) lockedError() error {
+	return NewAssertionError(ErrLocked, x.path, "file %s is locked", x)
+}
+
+type lockTableKey struct {
+	fs   afero.Fs
+	path string
+}
+
+// lockTable holds the locks of files on other filesystems than the OS one.
+var lockTable sync.Map
+
+// tableLock acquires a lock using the lock table of this process.
+func (x File) tableLock(exclusive, blocking bool) (*FileLock, error) {
+	value, _ := lockTable.LoadOrStore(lockTableKey{x.fs, x.Path()}, &sync.RWMutex{})
+	mutex := value.(*sync.RWMutex)
+
+	result := &FileLock{file: x}
+	switch {
+	case exclusive && blocking:
+		mutex.Lock()
+	case exclusive && !mutex.TryLock():
+		return nil, x.lockedError()
+	case !exclusive && blocking:
+		mutex.RLock()
+	case !exclusive && !mutex.TryRLock():
+		return nil, x.lockedError()
+	}
+	result.unlock = func() error {
+		if exclusive {
+			mutex.Unlock()
+		} else {
+			mutex.RUnlock()
+		}
+		return nil
+	}
+	return result, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix || solaris || aix
+
+package gofs
+
+// osLock falls back to the lock table of this process where flock is not available.
+func (x File) osLock(exclusive, blocking bool) (*FileLock, error) {
+	return x.tableLock(exclusive, blocking)
+}
@@ -0,0 +1,72 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func testLock(t *testing.T, f File) {
+	a := assert.New(t)
+
+	lock, err := f.Lock()
+	a.Nil(err)
+	a.Equal(f, lock.File())
+	_, err = f.TryLock()
+	a.ErrorIs(err, ErrLocked)
+	a.Nil(lock.Unlock())
+	a.Nil(lock.Unlock())
+
+	lock, err = f.TryLock()
+	a.Nil(err)
+	a.Nil(lock.Unlock())
+
+	// shared locks
+	first, err := f.RLock()
+	a.Nil(err)
+	second, err := f.RLock()
+	a.Nil(err)
+	_, err = f.TryLock()
+	a.ErrorIs(err, ErrLocked)
+	a.Nil(first.Unlock())
+	a.Nil(second.Unlock())
+
+	// concurrent read-modify-write cycles are serialized
+	a.Nil(f.SetContentString("0"))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.Nil(f.WithLock(func() error {
+				counter, err := strconv.Atoi(f.MustContentString())
+				if err != nil {
+					return err
+				}
+				return f.SetContentString(strconv.Itoa(counter + 1))
+			}))
+		}()
+	}
+	wg.Wait()
+	a.Equal("20", f.MustContentString())
+}
+
+func TestFile_Lock(t *testing.T) {
+	testLock(t, FileWithFs("/tmp/locked.txt", afero.NewMemMapFs()))
+}
+
+func TestFile_Lock_OsFs(t *testing.T) {
+	f := DirWithFs(t.TempDir(), afero.NewOsFs()).MustFileAt("locked.txt")
+	testLock(t, f)
+
+	// the lock file is shared by all instances of the file
+	a := assert.New(t)
+	a.True(FileAt(f.lockPath()).Exists())
+	lock, err := f.Lock()
+	a.Nil(err)
+	_, err = FileAt(f.Path()).TryLock()
+	a.ErrorIs(err, ErrLocked)
+	a.Nil(lock.Unlock())
+}
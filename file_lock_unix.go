@@ -0,0 +1,52 @@
+//go:build unix && !solaris && !aix
+
+package gofs
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/juju/errors"
+)
+
+// osLock acquires a lock with flock on the lock file of x.
+func (x File) osLock(exclusive, blocking bool) (*FileLock, error) {
+	f, err := os.OpenFile(x.lockPath(), os.O_RDWR|os.O_CREATE, x.createPermissions)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not open lock file for %s", x)
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if !blocking {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err == syscall.EWOULDBLOCK {
+		_ = f.Close()
+		return nil, x.lockedError()
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Annotatef(err, "could not lock %s", x)
+	}
+
+	return &FileLock{
+		file: x,
+		unlock: func() error {
+			err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+			closeErr := f.Close()
+			if err != nil {
+				return errors.Annotatef(err, "could not unlock %s", x)
+			}
+			return closeErr
+		},
+	}, nil
+}
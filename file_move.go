@@ -0,0 +1,76 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+)
+
+// MoveTo moves this file to target, replacing target if it exists. A rename is used if both files
+// are on the same filesystem. If they are not or the rename fails because the target is on another
+// device, the file is copied, the copy is verified and the source is removed.
+func (x File) MoveTo(target File) error {
+	return x.MoveToWithOptions(target, MoveOptions{})
+}
+
+// MoveToWithOptions moves this file to target like MoveTo.
+func (x File) MoveToWithOptions(target File, opts MoveOptions) error {
+	if opts.NoOverwrite && target.Exists() {
+		return NewFileExistsError(target.Path())
+	}
+
+	if sameFs(x.fs, target.fs) {
+		err := x.fs.Rename(x.Path(), target.Path())
+		if err == nil || !needsCopyFallback(err) {
+			return err
+		}
+	}
+
+	err := x.copyWithOptions(target, moveCopyOptions(x.fs, target.fs))
+	if err != nil {
+		return errors.Annotatef(err, "could not copy %s to %s", x, target)
+	}
+	err = verifyCopy(x, target)
+	if err != nil {
+		return err
+	}
+	return x.fs.Remove(x.Path())
+}
+
+// MoveToDir moves this file into target keeping its filename.
+func (x File) MoveToDir(target Dir) error {
+	return x.MoveTo(FileAtDir(target, x.Filename()))
+}
+
+func (x File) MustMoveTo(target File) File {
+	err := x.MoveTo(target)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// verifyCopy makes sure the content of target equals the one of source.
+func verifyCopy(source, target File) error {
+	sourceSize, err := source.FilesizeE()
+	if err != nil {
+		return errors.Annotatef(err, "could not get size of %s", source)
+	}
+	targetSize, err := target.FilesizeE()
+	if err != nil {
+		return errors.Annotatef(err, "could not get size of copy %s", target)
+	}
+	if sourceSize != targetSize {
+		return errors.Errorf("copy %s of %s has a different size", target, source)
+	}
+	sourceHash, err := source.Md5Hash()
+	if err != nil {
+		return err
+	}
+	targetHash, err := target.Md5Hash()
+	if err != nil {
+		return err
+	}
+	if sourceHash != targetHash {
+		return errors.Errorf("copy %s of %s has different content", target, source)
+	}
+	return nil
+}
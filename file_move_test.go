@@ -0,0 +1,81 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFile_MoveTo(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	dir := DirWithFs("/tmp/move", fs).MustEnsure(0750)
+
+	src := dir.MustFileAt("a.txt")
+	a.Nil(src.SetContentString("a"))
+	target := dir.MustFileAt("b.txt")
+	a.Nil(src.MoveTo(target))
+	a.True(src.NotExists())
+	a.Equal("a", target.MustContentString())
+
+	// overwrite protection
+	a.Nil(src.SetContentString("new"))
+	var existsErr *FileExistsError
+	a.ErrorAs(src.MoveToWithOptions(target, MoveOptions{NoOverwrite: true}), &existsErr)
+	a.True(src.Exists())
+
+	// move to another backend
+	other := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.MoveToDir(other))
+	a.True(src.NotExists())
+	a.Equal("new", other.MustFileAt("a.txt").MustContentString())
+}
+
+func TestDir_MoveTo(t *testing.T) {
+	a := assert.New(t)
+	src := newWalkTestDir(t)
+
+	renamed := DirWithFs("/tmp/walk-moved", src.fs)
+	a.Nil(src.MoveTo(renamed))
+	a.True(src.NotExists())
+	a.Equal("b/c/deep.txt", renamed.MustFileAt("b/c/deep.txt").MustContentString())
+
+	other := DirWithFs(t.TempDir(), afero.NewOsFs()).MustDirAt("moved")
+	a.Nil(renamed.MoveTo(other))
+	a.True(renamed.NotExists())
+	files, err := other.AllFiles(WalkOptions{})
+	a.Nil(err)
+	a.Len(files, 5)
+
+	var existsErr *DirExistsError
+	a.ErrorAs(src.MoveToWithOptions(renamed.MustEnsure(0750), MoveOptions{NoOverwrite: true}), &existsErr)
+}
+
+func TestDir_MoveTo_ExistingTarget(t *testing.T) {
+	for name, targetParent := range map[string]Dir{
+		"same fs":  DirWithFs("/tmp/targets", afero.NewMemMapFs()),
+		"other fs": DirWithFs(t.TempDir(), afero.NewOsFs()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			a := assert.New(t)
+			src := newWalkTestDir(t)
+			if name == "same fs" {
+				targetParent = DirWithFs(targetParent.Path(), src.fs)
+			}
+
+			// non-empty targets are rejected on every path
+			full := targetParent.MustDirAt("full").MustEnsure(0750)
+			a.Nil(full.MustFileAt("existing.txt").SetContentString("existing"))
+			var existsErr *DirExistsError
+			a.ErrorAs(src.MoveTo(full), &existsErr)
+			a.True(src.Exists())
+			a.True(full.MustFileAt("a.txt").NotExists())
+
+			// empty targets are replaced
+			empty := targetParent.MustDirAt("empty").MustEnsure(0750)
+			a.Equal(src, src.MustMoveTo(empty))
+			a.True(src.NotExists())
+			a.Equal("a.txt", empty.MustFileAt("a.txt").MustContentString())
+		})
+	}
+}
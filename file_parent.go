@@ -6,7 +6,7 @@ import (
 )
 
 func (x File) Dir() Dir {
-	return DirAt(path.Dir(x.path))
+	return DirWithFs(path.Dir(x.path), x.fs)
 }
 
 func (x File) ParentDir() Dir {
@@ -15,3 +15,7 @@ func NewFilePathNotRelativeError(path string) *FilePathNotRelativeError {
 func (x FilePathNotRelativeError) Error() string {
 	return fmt.Sprintf("file path was expected to be relative, but it was not: %s", x.path)
 }
+
+func (x FilePathNotRelativeError) Unwrap() error {
+	return ErrPathNotRelative
+}
@@ -0,0 +1,159 @@
+package gofs
+
+import (
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// globMatcher matches slash separated relative paths against a set of glob patterns.
+//
+// Patterns use the syntax of path.Match for single path elements. In addition, "**" matches any
+// number of path elements (including none), "{a,b}" expands to alternatives and a leading "!"
+// negates a pattern: paths matching a negated pattern are excluded even if another pattern matches.
+type globMatcher struct {
+	include [][]string
+	exclude [][]string
+	// maxDepth is the maximum number of path elements a matching path can have, 0 if unlimited.
+	maxDepth int
+}
+
+func compileGlob(patterns []string) (*globMatcher, error) {
+	result := &globMatcher{}
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/")
+		for _, expanded := range expandBraces(pattern) {
+			segments := strings.Split(expanded, "/")
+			for _, segment := range segments {
+				if _, err := path.Match(segment, ""); err != nil {
+					return nil, errors.Annotatef(err, "invalid glob pattern %s", pattern)
+				}
+			}
+			if negated {
+				result.exclude = append(result.exclude, segments)
+				continue
+			}
+			result.include = append(result.include, segments)
+		}
+	}
+
+	for _, segments := range result.include {
+		depth := len(segments)
+		for _, segment := range segments {
+			if segment == "**" {
+				depth = 0
+				break
+			}
+		}
+		if depth == 0 {
+			result.maxDepth = 0
+			break
+		}
+		if depth > result.maxDepth {
+			result.maxDepth = depth
+		}
+	}
+	return result, nil
+}
+
+// Match returns true if relPath matches at least one pattern and no negated one.
+func (x *globMatcher) Match(relPath string) bool {
+	parts := strings.Split(relPath, "/")
+	matched := false
+	for _, segments := range x.include {
+		if matchSegments(segments, parts) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, segments := range x.exclude {
+		if matchSegments(segments, parts) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(segments[0], parts[0])
+	return ok && matchSegments(segments[1:], parts[1:])
+}
+
+// expandBraces returns all alternatives described by the brace expressions in pattern.
+func expandBraces(pattern string) []string {
+	start, end := -1, -1
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+		if end != -1 {
+			break
+		}
+	}
+	if start == -1 || end == -1 {
+		return []string{pattern}
+	}
+
+	result := make([]string, 0)
+	prefix, suffix := pattern[:start], pattern[end+1:]
+	for _, alternative := range splitTopLevel(pattern[start+1 : end]) {
+		result = append(result, expandBraces(prefix+alternative+suffix)...)
+	}
+	return result
+}
+
+// splitTopLevel splits s at commas that are not nested in braces.
+func splitTopLevel(s string) []string {
+	result := make([]string, 0)
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	return append(result, s[last:])
+}
@@ -0,0 +1,144 @@
+package gofstest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jojomi/gofs"
+)
+
+// Tree describes the expected contents of a dir. Keys are slash separated paths relative to the
+// dir, values the expected file contents. Keys ending in a slash denote (empty) directories, their
+// values are ignored. Parent directories of listed entries are implied.
+type Tree map[string]string
+
+// DirExists fails the test if d does not exist.
+func DirExists(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckExists())
+}
+
+// DirNotExists fails the test if d exists.
+func DirNotExists(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckNotExists())
+}
+
+// DirEmpty fails the test if d has contents.
+func DirEmpty(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckEmpty())
+}
+
+// DirNotEmpty fails the test if d has no contents.
+func DirNotEmpty(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckNotEmpty())
+}
+
+// DirReadable fails the test if d can not be read.
+func DirReadable(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckReadable())
+}
+
+// DirWritable fails the test if no files can be created in d.
+func DirWritable(t TB, d gofs.Dir) bool {
+	t.Helper()
+	return check(t, d.CheckWritable())
+}
+
+// DirTreeEquals fails the test if the contents of d do not match spec exactly. Missing and
+// unexpected entries are reported as a diff of the tree listing, differing file contents as a diff
+// per file.
+func DirTreeEquals(t TB, d gofs.Dir, spec Tree) bool {
+	t.Helper()
+	spec = spec.normalized()
+
+	got := make(map[string]gofs.File)
+	gotListing := make([]string, 0)
+	err := d.Walk(gofs.WalkOptions{}, func(entry gofs.WalkEntry) error {
+		relPath := filepath.ToSlash(entry.RelativePath())
+		if entry.IsDir() {
+			gotListing = append(gotListing, relPath+"/")
+			return nil
+		}
+		gotListing = append(gotListing, relPath)
+		got[relPath] = entry.File()
+		return nil
+	})
+	if !check(t, err) {
+		return false
+	}
+
+	wantListing := spec.listing()
+	sort.Strings(gotListing)
+	if strings.Join(wantListing, "\n") != strings.Join(gotListing, "\n") {
+		t.Errorf("dir %s has unexpected entries:\n%s", d, diff(strings.Join(wantListing, "\n")+"\n", strings.Join(gotListing, "\n")+"\n"))
+		return false
+	}
+
+	ok := true
+	for _, relPath := range wantListing {
+		f, isFile := got[relPath]
+		if !isFile {
+			continue
+		}
+		content, err := contentString(f)
+		if !check(t, err) {
+			ok = false
+			continue
+		}
+		if content != spec[relPath] {
+			t.Errorf("file %s has unexpected content:\n%s", relPath, diff(spec[relPath], content))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// WriteTree creates the files and dirs of spec in d. It fails the test if that is not possible.
+func WriteTree(t TB, d gofs.Dir, spec Tree) bool {
+	t.Helper()
+	for relPath, content := range spec.normalized() {
+		if strings.HasSuffix(relPath, "/") {
+			if !check(t, d.MustDirAt(filepath.FromSlash(relPath)).Ensure(0750)) {
+				return false
+			}
+			continue
+		}
+		f := d.MustFileAt(filepath.FromSlash(relPath))
+		if !check(t, f.EnsureDir(0750)) || !check(t, f.SetContentString(content)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalized returns the tree with leading slashes removed from its keys.
+func (x Tree) normalized() Tree {
+	result := make(Tree, len(x))
+	for relPath, content := range x {
+		result[strings.TrimPrefix(relPath, "/")] = content
+	}
+	return result
+}
+
+// listing returns all entries of the normalized tree including implied parent dirs, sorted.
+func (x Tree) listing() []string {
+	entries := make(map[string]bool)
+	for relPath := range x {
+		entries[relPath] = true
+		for parent := filepath.ToSlash(filepath.Dir(strings.TrimSuffix(relPath, "/"))); parent != "."; parent = filepath.ToSlash(filepath.Dir(parent)) {
+			entries[fmt.Sprintf("%s/", parent)] = true
+		}
+	}
+	result := make([]string, 0, len(entries))
+	for entry := range entries {
+		result = append(result, entry)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,67 @@
+package gofstest
+
+import (
+	"github.com/jojomi/gofs"
+)
+
+// FileExists fails the test if f does not exist.
+func FileExists(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckExists())
+}
+
+// FileNotExists fails the test if f exists.
+func FileNotExists(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckNotExists())
+}
+
+// FileEmpty fails the test if f has content.
+func FileEmpty(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckEmpty())
+}
+
+// FileNotEmpty fails the test if f has no content.
+func FileNotEmpty(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckNotEmpty())
+}
+
+// FileReadable fails the test if f can not be read.
+func FileReadable(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckReadable())
+}
+
+// FileWritable fails the test if f can not be written.
+func FileWritable(t TB, f gofs.File) bool {
+	t.Helper()
+	return check(t, f.CheckWritable())
+}
+
+// FileHasExtension fails the test if f does not have the given extension.
+func FileHasExtension(t TB, f gofs.File, ext gofs.FileExtension) bool {
+	t.Helper()
+	return check(t, f.CheckExtension(ext))
+}
+
+// FileMd5Hash fails the test if the content of f does not have the given md5 hash.
+func FileMd5Hash(t TB, f gofs.File, hash string) bool {
+	t.Helper()
+	return check(t, f.CheckMd5Hash(hash))
+}
+
+// FileContentEquals fails the test with a diff if the content of f is not want.
+func FileContentEquals(t TB, f gofs.File, want string) bool {
+	t.Helper()
+	got, err := contentString(f)
+	if !check(t, err) {
+		return false
+	}
+	if got != want {
+		t.Errorf("file %s has unexpected content:\n%s", f, diff(want, got))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+// Package gofstest provides test helpers for gofs files and dirs. In contrast to the Assert methods
+// of gofs, they report failures through testing.TB instead of panicking.
+package gofstest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jojomi/gofs"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// TB is the subset of testing.TB used by the helpers.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// check reports err as a test failure if it is not nil.
+func check(t TB, err error) bool {
+	t.Helper()
+	if err != nil {
+		t.Errorf("%s", err)
+		return false
+	}
+	return true
+}
+
+// diff returns a unified diff of want and got.
+func diff(want, got string) string {
+	result, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(want),
+		B:        difflib.SplitLines(got),
+		FromFile: "want",
+		ToFile:   "got",
+		Context:  2,
+	})
+	if err != nil {
+		return fmt.Sprintf("want:\n%s\ngot:\n%s", want, got)
+	}
+	return strings.TrimRight(result, "\n")
+}
+
+// contentString returns the content of f or an empty string if it can not be read.
+func contentString(f gofs.File) (string, error) {
+	content, err := f.ContentString()
+	if err != nil {
+		return "", fmt.Errorf("could not read content of %s: %w", f, err)
+	}
+	return content, nil
+}
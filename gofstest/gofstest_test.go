@@ -0,0 +1,93 @@
+package gofstest
+
+import (
+	"fmt"
+	"github.com/jojomi/gofs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// recorder collects reported failures instead of failing the test.
+type recorder struct {
+	errors []string
+}
+
+func (x *recorder) Helper() {}
+
+func (x *recorder) Errorf(format string, args ...any) {
+	x.errors = append(x.errors, fmt.Sprintf(format, args...))
+}
+
+func TestFileHelpers(t *testing.T) {
+	a := assert.New(t)
+	f := gofs.FileWithFs("/tmp/gofstest/file.log", afero.NewMemMapFs())
+
+	r := &recorder{}
+	a.False(FileExists(r, f))
+	a.Len(r.errors, 1)
+	a.Contains(r.errors[0], "should have existed")
+
+	a.Nil(f.SetContentString("first\nsecond\n"))
+	FileExists(t, f)
+	FileNotEmpty(t, f)
+	FileHasExtension(t, f, gofs.ExtLog)
+	FileContentEquals(t, f, "first\nsecond\n")
+
+	r = &recorder{}
+	a.False(FileContentEquals(r, f, "first\nchanged\n"))
+	a.Len(r.errors, 1)
+	a.Contains(r.errors[0], "-changed")
+	a.Contains(r.errors[0], "+second")
+}
+
+func TestDirTreeEquals(t *testing.T) {
+	a := assert.New(t)
+	d := gofs.DirWithFs("/tmp/gofstest", afero.NewMemMapFs())
+	spec := Tree{
+		"a.txt":     "a",
+		"sub/b.txt": "b",
+		"empty/":    "",
+	}
+	WriteTree(t, d, spec)
+	DirExists(t, d)
+	DirTreeEquals(t, d, spec)
+
+	r := &recorder{}
+	a.False(DirTreeEquals(r, d, Tree{
+		"a.txt":     "a",
+		"sub/c.txt": "c",
+	}))
+	a.Len(r.errors, 1)
+	a.Contains(r.errors[0], "-sub/c.txt")
+	a.Contains(r.errors[0], "+sub/b.txt")
+	a.Contains(r.errors[0], "+empty/")
+
+	r = &recorder{}
+	spec["sub/b.txt"] = "changed"
+	a.False(DirTreeEquals(r, d, spec))
+	a.Len(r.errors, 1)
+	a.Contains(r.errors[0], "sub/b.txt")
+
+	// keys with a leading slash are relative to the dir, too
+	r = &recorder{}
+	a.True(DirTreeEquals(r, d, Tree{"/a.txt": "a", "/sub/b.txt": "b", "/empty/": ""}))
+	a.Empty(r.errors)
+	r = &recorder{}
+	a.False(DirTreeEquals(r, d, Tree{"/a.txt": "changed", "/sub/b.txt": "b", "/empty/": ""}))
+	a.Len(r.errors, 1)
+	a.Contains(r.errors[0], "a.txt")
+}
+
+func TestTempDir(t *testing.T) {
+	var d gofs.Dir
+	var f gofs.File
+	t.Run("create", func(t *testing.T) {
+		d = TempDirWithFs(t, "gofstest", afero.NewMemMapFs())
+		f = TempFile(t, d, "*.txt")
+		DirExists(t, d)
+		FileExists(t, f)
+	})
+	FileNotExists(t, f)
+	DirNotExists(t, d)
+}
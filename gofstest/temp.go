@@ -0,0 +1,46 @@
+package gofstest
+
+import (
+	"testing"
+
+	"github.com/jojomi/gofs"
+	"github.com/spf13/afero"
+)
+
+// TempDir creates a temporary dir on the OS filesystem that is removed when the test finishes.
+func TempDir(t testing.TB, pattern string) gofs.Dir {
+	t.Helper()
+	return TempDirWithFs(t, pattern, afero.NewOsFs())
+}
+
+// TempDirWithFs creates a temporary dir on fs that is removed when the test finishes.
+func TempDirWithFs(t testing.TB, pattern string, fs afero.Fs) gofs.Dir {
+	t.Helper()
+	dir, err := gofs.TempDirWithFs(pattern, fs)
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	t.Cleanup(func() {
+		err := dir.Cleanup()
+		if err != nil {
+			t.Errorf("could not remove temp dir: %s", err)
+		}
+	})
+	return dir
+}
+
+// TempFile creates an empty temporary file in dir that is removed when the test finishes.
+func TempFile(t testing.TB, dir gofs.Dir, pattern string) gofs.File {
+	t.Helper()
+	file, err := gofs.TempFile(dir, pattern)
+	if err != nil {
+		t.Fatalf("could not create temp file: %s", err)
+	}
+	t.Cleanup(func() {
+		err := file.Cleanup()
+		if err != nil {
+			t.Errorf("could not remove temp file: %s", err)
+		}
+	})
+	return file
+}
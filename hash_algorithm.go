@@ -0,0 +1,95 @@
+package gofs
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"hash/crc32"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/blake2b"
+	"lukechampine.com/blake3"
+)
+
+// HashAlgorithm is a named hash function usable to compute file digests.
+type HashAlgorithm struct {
+	name    string
+	factory func() hash.Hash
+}
+
+var (
+	hashAlgorithmsMutex sync.RWMutex
+	hashAlgorithms      = make(map[string]HashAlgorithm)
+)
+
+var (
+	HashMd5        = RegisterHashAlgorithm("md5", md5.New)
+	HashSha1       = RegisterHashAlgorithm("sha1", sha1.New)
+	HashSha256     = RegisterHashAlgorithm("sha256", sha256.New)
+	HashSha512     = RegisterHashAlgorithm("sha512", sha512.New)
+	HashBlake2b256 = RegisterHashAlgorithm("blake2b-256", mustHash(blake2b.New256))
+	HashBlake2b512 = RegisterHashAlgorithm("blake2b-512", mustHash(blake2b.New512))
+	HashBlake3     = RegisterHashAlgorithm("blake3", func() hash.Hash { return blake3.New(32, nil) })
+	HashCrc32      = RegisterHashAlgorithm("crc32", func() hash.Hash { return crc32.NewIEEE() })
+)
+
+// RegisterHashAlgorithm makes a hash function available under name, replacing any algorithm
+// registered with the same name before. Names are case insensitive.
+func RegisterHashAlgorithm(name string, factory func() hash.Hash) HashAlgorithm {
+	algorithm := HashAlgorithm{
+		name:    strings.ToLower(name),
+		factory: factory,
+	}
+
+	hashAlgorithmsMutex.Lock()
+	defer hashAlgorithmsMutex.Unlock()
+	hashAlgorithms[algorithm.name] = algorithm
+
+	return algorithm
+}
+
+// HashAlgorithmByName returns the registered algorithm with the given name.
+func HashAlgorithmByName(name string) (HashAlgorithm, error) {
+	hashAlgorithmsMutex.RLock()
+	defer hashAlgorithmsMutex.RUnlock()
+
+	algorithm, ok := hashAlgorithms[strings.ToLower(name)]
+	if !ok {
+		return HashAlgorithm{}, errors.NotFoundf("hash algorithm %s", name)
+	}
+	return algorithm, nil
+}
+
+// Name returns the name the algorithm was registered with.
+func (x HashAlgorithm) Name() string {
+	return x.name
+}
+
+// New returns a new hash.Hash computing this algorithm.
+func (x HashAlgorithm) New() hash.Hash {
+	return x.factory()
+}
+
+// Size returns the number of bytes of digests of this algorithm.
+func (x HashAlgorithm) Size() int {
+	return x.New().Size()
+}
+
+func (x HashAlgorithm) String() string {
+	return x.name
+}
+
+// mustHash adapts the keyed hash constructors of golang.org/x/crypto that only fail for invalid keys.
+func mustHash(factory func(key []byte) (hash.Hash, error)) func() hash.Hash {
+	return func() hash.Hash {
+		h, err := factory(nil)
+		if err != nil {
+			panic(err)
+		}
+		return h
+	}
+}
@@ -0,0 +1,227 @@
+package gofs
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// GitIgnoreFile is the name of git's ignore files.
+const GitIgnoreFile = ".gitignore"
+
+// IgnoreOptions selects ignore rules in .gitignore syntax to respect when listing, walking or
+// copying a Dir.
+type IgnoreOptions struct {
+	// IgnoreFile is the name of the ignore files to read, e.g. GitIgnoreFile or ".gofsignore". An
+	// ignore file applies to the dir it is in and all of its subdirectories, rules in deeper files
+	// take precedence.
+	IgnoreFile string
+	// Patterns are additional rules in ignore file syntax. They are relative to the processed dir and
+	// have the lowest precedence.
+	Patterns []string
+}
+
+func (x IgnoreOptions) isEmpty() bool {
+	return x.IgnoreFile == "" && len(x.Patterns) == 0
+}
+
+// IgnoreMatcher decides if paths below a root dir are ignored according to ignore files and
+// patterns. Ignore files are read lazily when paths in their directories are matched.
+type IgnoreMatcher struct {
+	root   Dir
+	opts   IgnoreOptions
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+type ignoreRule struct {
+	// base is the slash separated dir the rule is relative to, "" for the root.
+	base     string
+	segments []string
+	negated  bool
+	dirOnly  bool
+}
+
+// NewIgnoreMatcher returns a matcher for paths below root.
+func NewIgnoreMatcher(root Dir, opts IgnoreOptions) *IgnoreMatcher {
+	result := &IgnoreMatcher{
+		root:   root,
+		opts:   opts,
+		loaded: make(map[string]bool),
+	}
+	result.addRules("", opts.Patterns)
+	return result
+}
+
+// Ignored returns true if path is ignored. path can be absolute or relative to the root dir. A path
+// is also ignored if any of its parent dirs is.
+func (x *IgnoreMatcher) Ignored(p string, isDir bool) (bool, error) {
+	if filepath.IsAbs(p) {
+		rel, err := filepath.Rel(x.root.Path(), p)
+		if err != nil {
+			return false, err
+		}
+		p = rel
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+	if p == "." {
+		return false, nil
+	}
+
+	err := x.load("")
+	if err != nil {
+		return false, err
+	}
+	parts := strings.Split(p, "/")
+	for i := 1; i <= len(parts); i++ {
+		current := strings.Join(parts[:i], "/")
+		currentIsDir := isDir || i < len(parts)
+		if x.matches(current, currentIsDir) {
+			return true, nil
+		}
+		if i < len(parts) {
+			err = x.load(current)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// load reads the ignore file in the dir at the slash separated relative path dir once.
+func (x *IgnoreMatcher) load(dir string) error {
+	if x.opts.IgnoreFile == "" || x.loaded[dir] {
+		return nil
+	}
+	x.loaded[dir] = true
+
+	ignoreFile := FileWithFs(filepath.Join(x.root.Path(), filepath.FromSlash(dir), x.opts.IgnoreFile), x.root.fs)
+	f, err := ignoreFile.fs.Open(ignoreFile.Path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Annotatef(err, "could not read ignore file %s", ignoreFile)
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Annotatef(err, "could not read ignore file %s", ignoreFile)
+	}
+	x.addRules(dir, lines)
+	return nil
+}
+
+// matches returns if the slash separated relative path p itself is ignored, not looking at its
+// parents.
+func (x *IgnoreMatcher) matches(p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range x.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := p
+		if rule.base != "" {
+			if !strings.HasPrefix(p, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(p, rule.base+"/")
+		}
+		if matchSegments(rule.segments, strings.Split(rel, "/")) {
+			ignored = !rule.negated
+		}
+	}
+	return ignored
+}
+
+func (x *IgnoreMatcher) addRules(base string, lines []string) {
+	for _, line := range lines {
+		rule, ok := parseIgnoreLine(line)
+		if !ok {
+			continue
+		}
+		rule.base = base
+		x.rules = append(x.rules, rule)
+	}
+}
+
+// parseIgnoreLine parses a single line of an ignore file. false is returned for blank lines and
+// comments.
+func parseIgnoreLine(line string) (ignoreRule, bool) {
+	rule := ignoreRule{}
+	line = strings.TrimSuffix(line, "\r")
+
+	// trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimSuffix(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negated = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// patterns containing a slash are relative to the ignore file's dir, others match at any level
+	anchored := strings.Contains(line, "/")
+	line = path.Clean(strings.TrimPrefix(line, "/"))
+	rule.segments = strings.Split(line, "/")
+	if !anchored {
+		rule.segments = append([]string{"**"}, rule.segments...)
+	}
+	return rule, true
+}
+
+// walkIgnore is used during a walk where the dirs are visited top down, so parent dirs never have
+// to be checked.
+type walkIgnore struct {
+	matcher *IgnoreMatcher
+}
+
+func newWalkIgnore(root Dir, opts IgnoreOptions) *walkIgnore {
+	if opts.isEmpty() {
+		return nil
+	}
+	return &walkIgnore{matcher: NewIgnoreMatcher(root, opts)}
+}
+
+// enter loads the ignore file of the dir at the given path relative to the walk root.
+func (x *walkIgnore) enter(rel string) error {
+	if x == nil {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	return x.matcher.load(rel)
+}
+
+func (x *walkIgnore) ignored(rel string, isDir bool) bool {
+	if x == nil {
+		return false
+	}
+	return x.matcher.matches(filepath.ToSlash(rel), isDir)
+}
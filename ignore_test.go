@@ -0,0 +1,86 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newIgnoreTestDir(t *testing.T) Dir {
+	d := DirWithFs("/tmp/project", afero.NewMemMapFs())
+	contents := map[string]string{
+		".gitignore":              "# build output\n/build/\n*.log\n!keep.log\nnode_modules/\n",
+		"main.go":                 "",
+		"debug.log":               "",
+		"keep.log":                "",
+		"build/out":               "",
+		"node_modules/x/index.js": "",
+		"sub/.gitignore":          "*.tmp\n!important.log\n",
+		"sub/a.tmp":               "",
+		"sub/important.log":       "",
+		"sub/other.log":           "",
+		"sub/build/out":           "",
+		".git/HEAD":               "",
+	}
+	for name, content := range contents {
+		assert.Nil(t, d.MustFileAt(name).MustEnsureDir(0750).SetContentString(content))
+	}
+	return d
+}
+
+func TestDir_Walk_Ignore(t *testing.T) {
+	a := assert.New(t)
+	d := newIgnoreTestDir(t)
+
+	files, err := d.AllFiles(WalkOptions{Ignore: IgnoreOptions{
+		IgnoreFile: GitIgnoreFile,
+		Patterns:   []string{".git/"},
+	}})
+	a.Nil(err)
+	a.Equal([]string{".gitignore", "keep.log", "main.go", "sub/.gitignore", "sub/build/out", "sub/important.log"}, relativePaths(d, files))
+
+	files, err = d.FilesWithOptions(WalkOptions{Ignore: IgnoreOptions{IgnoreFile: GitIgnoreFile}})
+	a.Nil(err)
+	a.Equal([]string{".gitignore", "keep.log", "main.go"}, relativePaths(d, files))
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	a := assert.New(t)
+	d := newIgnoreTestDir(t)
+	m := NewIgnoreMatcher(d, IgnoreOptions{IgnoreFile: GitIgnoreFile})
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"main.go", false, false},
+		{"debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"build/out", false, true},
+		{"sub/build/out", false, false},
+		{"node_modules/x/index.js", false, true},
+		{"sub/a.tmp", false, true},
+		{"sub/important.log", false, false},
+		{d.MustFileAt("sub/other.log").Path(), false, true},
+	}
+	for _, tt := range tests {
+		ignored, err := m.Ignored(tt.path, tt.isDir)
+		a.Nil(err)
+		a.Equal(tt.want, ignored, tt.path)
+	}
+}
+
+func TestDir_CopyTo_Ignore(t *testing.T) {
+	a := assert.New(t)
+	d := newIgnoreTestDir(t)
+	target := DirWithFs("/tmp/copy", d.fs)
+
+	a.Nil(d.CopyTo(target, CopyOptions{Ignore: IgnoreOptions{IgnoreFile: GitIgnoreFile, Patterns: []string{".git/"}}}))
+	a.True(target.MustFileAt("main.go").Exists())
+	a.True(target.MustFileAt("debug.log").NotExists())
+	a.True(target.MustDirAt("node_modules").NotExists())
+	a.True(target.MustDirAt(".git").NotExists())
+}
@@ -0,0 +1,209 @@
+package gofs
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// FileType is the type of a filesystem entry.
+type FileType int
+
+const (
+	FileTypeRegular FileType = iota
+	FileTypeDir
+	FileTypeSymlink
+	FileTypeNamedPipe
+	FileTypeSocket
+	FileTypeDevice
+	FileTypeCharDevice
+	// FileTypeIrregular is any other type of file.
+	FileTypeIrregular
+)
+
+func fileTypeFromMode(mode os.FileMode) FileType {
+	switch {
+	case mode.IsRegular():
+		return FileTypeRegular
+	case mode.IsDir():
+		return FileTypeDir
+	case mode&os.ModeSymlink != 0:
+		return FileTypeSymlink
+	case mode&os.ModeNamedPipe != 0:
+		return FileTypeNamedPipe
+	case mode&os.ModeSocket != 0:
+		return FileTypeSocket
+	case mode&os.ModeCharDevice != 0:
+		return FileTypeCharDevice
+	case mode&os.ModeDevice != 0:
+		return FileTypeDevice
+	default:
+		return FileTypeIrregular
+	}
+}
+
+func (x FileType) String() string {
+	switch x {
+	case FileTypeRegular:
+		return "regular file"
+	case FileTypeDir:
+		return "dir"
+	case FileTypeSymlink:
+		return "symlink"
+	case FileTypeNamedPipe:
+		return "named pipe"
+	case FileTypeSocket:
+		return "socket"
+	case FileTypeDevice:
+		return "device"
+	case FileTypeCharDevice:
+		return "char device"
+	default:
+		return "irregular file"
+	}
+}
+
+// Metadata describes a file or dir. Fields the filesystem does not provide have their zero value,
+// except for Uid and Gid which are -1 then.
+type Metadata struct {
+	Name string
+	Size int64
+	Mode os.FileMode
+	Type FileType
+	// ModTime is the time of the last modification of the content.
+	ModTime time.Time
+	// AccessTime is the time of the last access.
+	AccessTime time.Time
+	// ChangeTime is the time of the last change of the content or metadata.
+	ChangeTime time.Time
+	Uid        int
+	Gid        int
+	Inode      uint64
+	// Links is the number of hard links.
+	Links uint64
+
+	info os.FileInfo
+}
+
+// FileInfo returns the os.FileInfo the metadata was read from.
+func (x Metadata) FileInfo() os.FileInfo {
+	return x.info
+}
+
+// Info returns the metadata of this file. Symlinks are not followed, so Type is FileTypeSymlink for
+// them.
+func (x File) Info() (Metadata, error) {
+	return readMetadata(x.fs, x.Path())
+}
+
+// Info returns the metadata of this dir. Symlinks are not followed, so Type is FileTypeSymlink for
+// them.
+func (x Dir) Info() (Metadata, error) {
+	return readMetadata(x.fs, x.Path())
+}
+
+func readMetadata(fs afero.Fs, p string) (Metadata, error) {
+	info, err := lstat(fs, p)
+	if err != nil {
+		return Metadata{}, err
+	}
+	result := Metadata{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		Type:    fileTypeFromMode(info.Mode()),
+		ModTime: info.ModTime(),
+		Uid:     -1,
+		Gid:     -1,
+		info:    info,
+	}
+	addSysMetadata(&result, info)
+	return result, nil
+}
+
+// Chmod changes the mode of this file.
+func (x File) Chmod(mode os.FileMode) error {
+	return changeMetadata(x.fs, x.Path(), "changing the mode", func() error {
+		return x.fs.Chmod(x.Path(), mode)
+	})
+}
+
+// Chtimes changes the access and modification times of this file.
+func (x File) Chtimes(atime, mtime time.Time) error {
+	return changeMetadata(x.fs, x.Path(), "changing times", func() error {
+		return x.fs.Chtimes(x.Path(), atime, mtime)
+	})
+}
+
+// Chown changes the owner of this file.
+func (x File) Chown(uid, gid int) error {
+	return changeMetadata(x.fs, x.Path(), "changing the owner", func() error {
+		return x.fs.Chown(x.Path(), uid, gid)
+	})
+}
+
+// Touch creates this file empty if it does not exist and sets its access and modification times to
+// the current time otherwise.
+func (x File) Touch() error {
+	exists, err := x.ExistsE()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return x.SetContent(nil)
+	}
+	now := time.Now()
+	return x.Chtimes(now, now)
+}
+
+// Chmod changes the mode of this dir.
+func (x Dir) Chmod(mode os.FileMode) error {
+	return changeMetadata(x.fs, x.Path(), "changing the mode", func() error {
+		return x.fs.Chmod(x.Path(), mode)
+	})
+}
+
+// Chtimes changes the access and modification times of this dir.
+func (x Dir) Chtimes(atime, mtime time.Time) error {
+	return changeMetadata(x.fs, x.Path(), "changing times", func() error {
+		return x.fs.Chtimes(x.Path(), atime, mtime)
+	})
+}
+
+// Chown changes the owner of this dir.
+func (x Dir) Chown(uid, gid int) error {
+	return changeMetadata(x.fs, x.Path(), "changing the owner", func() error {
+		return x.fs.Chown(x.Path(), uid, gid)
+	})
+}
+
+// changeMetadata runs change and returns a NotSupported error wrapping ErrUnsupported if the
+// filesystem can not change metadata at all, so that it can be told apart from missing
+// permissions.
+func changeMetadata(fs afero.Fs, p string, operation string, change func() error) error {
+	if isReadOnlyFs(fs) {
+		return errors.NewNotSupported(ErrUnsupported, fmt.Sprintf("%s of %s on read-only filesystem %s", operation, p, fs.Name()))
+	}
+	err := change()
+	// afero.ReadOnlyFs returns a bare EPERM, also when wrapped like in a BasePathFs, while
+	// missing permissions on the OS filesystem are reported wrapped in an os.PathError
+	if stderrors.Is(err, ErrUnsupported) || stderrors.Is(err, syscall.EROFS) || err == syscall.EPERM {
+		return errors.NewNotSupported(ErrUnsupported, fmt.Sprintf("%s of %s on %s: %v", operation, p, fs.Name(), err))
+	}
+	return err
+}
+
+// isReadOnlyFs returns true for the afero backends that can not be changed at all.
+func isReadOnlyFs(fs afero.Fs) bool {
+	switch fs.(type) {
+	case *afero.ReadOnlyFs, afero.FromIOFS:
+		return true
+	}
+	name := fs.Name()
+	return name == "zipfs" || name == "tarfs"
+}
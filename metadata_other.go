@@ -0,0 +1,11 @@
+//go:build !unix
+
+package gofs
+
+import (
+	"os"
+)
+
+// addSysMetadata adds the metadata only available from the system specific info.
+func addSysMetadata(m *Metadata, info os.FileInfo) {
+}
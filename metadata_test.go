@@ -0,0 +1,128 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFile_Info(t *testing.T) {
+	a := assert.New(t)
+	d := DirWithFs(t.TempDir(), afero.NewOsFs())
+	f := d.MustFileAt("info.txt")
+	a.Nil(f.SetContentString("content"))
+	a.Nil(f.Chmod(0604))
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.Nil(f.Chtimes(modTime, modTime))
+
+	info, err := f.Info()
+	a.Nil(err)
+	a.Equal("info.txt", info.Name)
+	a.Equal(int64(7), info.Size)
+	a.Equal(os.FileMode(0604), info.Mode)
+	a.Equal(FileTypeRegular, info.Type)
+	a.Equal("regular file", info.Type.String())
+	a.True(modTime.Equal(info.ModTime))
+	a.Equal("info.txt", info.FileInfo().Name())
+	if runtime.GOOS == "linux" {
+		a.Equal(os.Getuid(), info.Uid)
+		a.Equal(os.Getgid(), info.Gid)
+		a.NotZero(info.Inode)
+		a.Equal(uint64(1), info.Links)
+		a.True(modTime.Equal(info.AccessTime))
+		a.False(info.ChangeTime.IsZero())
+	}
+
+	a.Nil(os.Link(f.Path(), filepath.Join(d.Path(), "hardlink.txt")))
+	a.Nil(os.Symlink("info.txt", filepath.Join(d.Path(), "symlink.txt")))
+	if runtime.GOOS == "linux" {
+		info, err = f.Info()
+		a.Nil(err)
+		a.Equal(uint64(2), info.Links)
+	}
+	info, err = d.MustFileAt("symlink.txt").Info()
+	a.Nil(err)
+	a.Equal(FileTypeSymlink, info.Type)
+
+	info, err = d.Info()
+	a.Nil(err)
+	a.Equal(FileTypeDir, info.Type)
+
+	_, err = d.MustFileAt("missing.txt").Info()
+	a.ErrorIs(err, ErrNotExist)
+}
+
+func TestFile_Info_MemMapFs(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	f := FileWithFs("/tmp/info.txt", fs)
+	a.Nil(f.SetContentString("content"))
+
+	info, err := f.Info()
+	a.Nil(err)
+	a.Equal(int64(7), info.Size)
+	a.Equal(-1, info.Uid)
+	a.Equal(-1, info.Gid)
+	a.Zero(info.Inode)
+	a.True(info.AccessTime.IsZero())
+
+	a.Nil(f.Chmod(0600))
+	a.Nil(f.Chown(1000, 1000))
+	a.Nil(f.Dir().Chmod(0700))
+	info, err = f.Dir().Info()
+	a.Nil(err)
+	a.Equal(os.ModeDir|0700, info.Mode)
+}
+
+func TestFile_Touch(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	f := FileWithFs("/tmp/touched.txt", fs).SetCreatePermissions(0600)
+
+	a.Nil(f.Touch())
+	info, err := f.Info()
+	a.Nil(err)
+	a.Equal(int64(0), info.Size)
+	a.Equal(os.FileMode(0600), info.Mode)
+
+	a.Nil(f.SetContentString("content"))
+	old := time.Now().Add(-time.Hour)
+	a.Nil(f.Chtimes(old, old))
+	a.Nil(f.Touch())
+	info, err = f.Info()
+	a.Nil(err)
+	a.True(info.ModTime.After(old.Add(time.Minute)))
+	a.Equal("content", f.MustContentString())
+}
+
+func TestFile_Chmod_Unsupported(t *testing.T) {
+	a := assert.New(t)
+	base := afero.NewMemMapFs()
+	a.Nil(FileWithFs("/tmp/file.txt", base).SetContentString("content"))
+	f := FileWithFs("/tmp/file.txt", afero.NewReadOnlyFs(base))
+
+	for _, err := range []error{f.Chmod(0600), f.Chown(0, 0), f.Chtimes(time.Now(), time.Now()), f.Dir().Chmod(0700)} {
+		a.ErrorIs(err, ErrUnsupported)
+		a.True(errors.IsNotSupported(err))
+		a.ErrorContains(err, "read-only")
+	}
+
+	// wrapped read-only filesystems
+	wrapped := FileWithFs("/file.txt", afero.NewBasePathFs(afero.NewReadOnlyFs(base), "/tmp"))
+	for _, err := range []error{wrapped.Chmod(0600), wrapped.Chown(0, 0), wrapped.Chtimes(time.Now(), time.Now())} {
+		a.ErrorIs(err, ErrUnsupported)
+		a.True(errors.IsNotSupported(err))
+	}
+
+	// missing permissions are not reported as unsupported
+	if os.Getuid() != 0 && runtime.GOOS != "windows" {
+		err := FileWithFs(t.TempDir(), afero.NewOsFs()).Chown(0, 0)
+		a.Error(err)
+		a.NotErrorIs(err, ErrUnsupported)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build linux
+
+package gofs
+
+import (
+	"syscall"
+	"time"
+)
+
+// statTimes returns the access and change times of stat.
+func statTimes(stat *syscall.Stat_t) (time.Time, time.Time) {
+	return time.Unix(stat.Atim.Unix()), time.Unix(stat.Ctim.Unix())
+}
@@ -0,0 +1,13 @@
+//go:build unix && !linux && !darwin && !freebsd && !netbsd
+
+package gofs
+
+import (
+	"syscall"
+	"time"
+)
+
+// statTimes returns the access and change times of stat, which are not read on this system.
+func statTimes(stat *syscall.Stat_t) (time.Time, time.Time) {
+	return time.Time{}, time.Time{}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package gofs
+
+import (
+	"os"
+	"syscall"
+)
+
+// addSysMetadata adds the metadata only available from the system specific info.
+func addSysMetadata(m *Metadata, info os.FileInfo) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	m.Uid = int(stat.Uid)
+	m.Gid = int(stat.Gid)
+	m.Inode = uint64(stat.Ino)
+	m.Links = uint64(stat.Nlink)
+	m.AccessTime, m.ChangeTime = statTimes(stat)
+}
@@ -0,0 +1,30 @@
+package gofs
+
+import (
+	"syscall"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// MoveOptions configures moving files and dirs.
+type MoveOptions struct {
+	// NoOverwrite makes a move fail with a FileExistsError or DirExistsError if the target exists.
+	NoOverwrite bool
+}
+
+// needsCopyFallback returns true if a failed rename can be replaced by copying and deleting.
+func needsCopyFallback(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
+
+// moveCopyOptions returns the options used when a move has to copy between filesystems.
+func moveCopyOptions(source, target afero.Fs) CopyOptions {
+	_, canRead := source.(afero.LinkReader)
+	_, canLink := target.(afero.Linker)
+	return CopyOptions{
+		PreserveMode:     true,
+		PreserveTimes:    true,
+		PreserveSymlinks: canRead && canLink,
+	}
+}
@@ -0,0 +1,114 @@
+package gofs
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// PIDFile guards against running a program more than once by holding a lock on a file containing
+// the PID of the current process.
+type PIDFile struct {
+	file File
+	pid  int
+	lock *FileLock
+}
+
+// NewPIDFile writes the PID of the current process to file and keeps it locked (see File.Lock)
+// until Close is called. If another instance holds the lock or the file contains the PID of another
+// running process, an AssertionError wrapping ErrProcessRunning is returned. PID files of processes
+// that are not running anymore are replaced.
+func NewPIDFile(file File) (*PIDFile, error) {
+	lock, err := file.TryLock()
+	if errors.Is(err, ErrLocked) {
+		return nil, NewAssertionError(ErrProcessRunning, file.path, "PID file %s is locked by another process", file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := readPID(file)
+	if err == nil && pid != os.Getpid() && processAlive(pid) {
+		_ = lock.Unlock()
+		return nil, NewAssertionError(ErrProcessRunning, file.path, "PID file %s belongs to running process %d", file, pid)
+	}
+
+	result := &PIDFile{
+		file: file,
+		pid:  os.Getpid(),
+		lock: lock,
+	}
+	err = file.AtomicSetContentString(strconv.Itoa(result.pid) + "\n")
+	if err != nil {
+		_ = lock.Unlock()
+		return nil, err
+	}
+	return result, nil
+}
+
+// File returns the PID file.
+func (x *PIDFile) File() File {
+	return x.file
+}
+
+// PID returns the PID written to the file.
+func (x *PIDFile) PID() int {
+	return x.pid
+}
+
+// Close removes the PID file if it still contains the PID written by NewPIDFile, removes the lock
+// file on the OS filesystem and releases the lock. Calling it more than once has no effect.
+func (x *PIDFile) Close() error {
+	if x.lock == nil {
+		return nil
+	}
+	var err error
+	if pid, readErr := readPID(x.file); readErr == nil && pid == x.pid {
+		err = x.file.Remove()
+	}
+	// the lock file is removed while the lock is still held
+	if _, isOs := x.file.fs.(*afero.OsFs); isOs {
+		removeErr := x.file.fs.Remove(x.file.lockPath())
+		if removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+			err = removeErr
+		}
+	}
+	unlockErr := x.lock.Unlock()
+	x.lock = nil
+	if err != nil {
+		return err
+	}
+	return unlockErr
+}
+
+// readPID returns the PID stored in file.
+func readPID(file File) (int, error) {
+	content, err := file.ContentString()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(content))
+}
+
+// processAlive returns if a process with the given PID is running. It probes /proc where available
+// and sends signal 0 otherwise, which fails with EPERM for running processes of other users.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if _, err := os.Stat("/proc/self"); err == nil {
+		_, err = os.Stat(fmt.Sprintf("/proc/%d", pid))
+		return err == nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
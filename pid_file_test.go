@@ -0,0 +1,62 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func testPIDFile(t *testing.T, f File) {
+	a := assert.New(t)
+
+	pidFile, err := NewPIDFile(f)
+	a.Nil(err)
+	a.Equal(os.Getpid(), pidFile.PID())
+	a.Equal(f, pidFile.File())
+	a.Equal(strconv.Itoa(os.Getpid())+"\n", f.MustContentString())
+
+	// a second instance is rejected
+	_, err = NewPIDFile(f)
+	a.ErrorIs(err, ErrProcessRunning)
+
+	a.Nil(pidFile.Close())
+	a.Nil(pidFile.Close())
+	a.True(f.NotExists())
+	a.True(FileWithFs(f.lockPath(), f.fs).NotExists())
+
+	// stale PID files are replaced
+	a.Nil(f.SetContentString("999999999\n"))
+	pidFile, err = NewPIDFile(f)
+	a.Nil(err)
+	a.Equal(strconv.Itoa(os.Getpid())+"\n", f.MustContentString())
+	a.Nil(pidFile.Close())
+
+	// PID files of running processes are kept even if they are not locked
+	a.Nil(f.SetContentString(strconv.Itoa(os.Getppid())))
+	_, err = NewPIDFile(f)
+	a.ErrorIs(err, ErrProcessRunning)
+	a.Equal(strconv.Itoa(os.Getppid()), f.MustContentString())
+
+	// the lock was released, so the file can be taken over once the process is gone
+	a.Nil(f.SetContentString("invalid"))
+	pidFile, err = NewPIDFile(f)
+	a.Nil(err)
+	a.Nil(pidFile.Close())
+}
+
+func TestPIDFile(t *testing.T) {
+	testPIDFile(t, FileWithFs("/run/app.pid", afero.NewMemMapFs()))
+}
+
+func TestPIDFile_OsFs(t *testing.T) {
+	testPIDFile(t, DirWithFs(t.TempDir(), afero.NewOsFs()).MustFileAt("app.pid"))
+}
+
+func TestProcessAlive(t *testing.T) {
+	a := assert.New(t)
+	a.True(processAlive(os.Getpid()))
+	a.False(processAlive(999999999))
+	a.False(processAlive(0))
+}
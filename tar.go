@@ -0,0 +1,271 @@
+package gofs
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// TarOptions configures creating tar archives.
+type TarOptions struct {
+	// CompressionLevel is used if the archive is compressed, which is derived from its file
+	// extension.
+	CompressionLevel CompressionLevel
+	// Reproducible strips owners and sets the modification time of all entries to ModTime or, if
+	// that is not set, the Unix epoch. Archives of equal contents are byte for byte identical then.
+	Reproducible bool
+	// ModTime is used as modification time of all entries if set.
+	ModTime time.Time
+	// Ignore selects ignore rules, ignored files and dirs are not archived.
+	Ignore IgnoreOptions
+}
+
+// TarTo writes the contents of this dir to the tar archive target. The archive is compressed with
+// gzip, zstd or xz if the filename of target ends with .gz/.tgz, .zst/.tzst or .xz/.txz, bzip2
+// compressed archives (.bz2/.tbz2) can only be extracted. Entries are added in lexical order with
+// paths relative to this dir, modes, mtimes and symlinks are preserved.
+func (x Dir) TarTo(target File, opts TarOptions) error {
+	w, err := target.fs.OpenFile(target.Path(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, target.createPermissions)
+	if err != nil {
+		return errors.Annotatef(err, "could not create tar archive %s", target)
+	}
+
+	err = x.writeCompressedTar(w, target, opts)
+	closeErr := w.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (x Dir) writeCompressedTar(w io.Writer, target File, opts TarOptions) error {
+	codec, compressed := compressionCodecFor(target)
+	if !compressed {
+		return x.writeTar(w, target, opts)
+	}
+
+	cw, err := codec.writer(w, opts.CompressionLevel)
+	if err != nil {
+		return errors.Annotatef(err, "could not create %s compressor for %s", codec.name, target)
+	}
+	err = x.writeTar(cw, target, opts)
+	closeErr := cw.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (x Dir) writeTar(w io.Writer, target File, opts TarOptions) error {
+	modTime := opts.ModTime
+	if opts.Reproducible && modTime.IsZero() {
+		modTime = time.Unix(0, 0)
+	}
+
+	tw := tar.NewWriter(w)
+	err := x.Walk(WalkOptions{Ignore: opts.Ignore}, func(entry WalkEntry) error {
+		if isArchiveOf(target, x, entry.Path()) {
+			return nil
+		}
+
+		info := entry.Info()
+		var linkTarget string
+		if info.Mode()&os.ModeSymlink != 0 {
+			var err error
+			linkTarget, err = readSymlink(x.fs, entry.Path())
+			if err != nil {
+				// the filesystem can not read symlinks, store what it points to
+				info, err = x.fs.Stat(entry.Path())
+				if err != nil {
+					return errors.Annotatef(err, "could not follow symlink %s", entry.Path())
+				}
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, linkTarget)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(entry.RelativePath())
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if !modTime.IsZero() {
+			header.ModTime = modTime.UTC()
+		}
+		if opts.Reproducible {
+			header.Uid, header.Gid = 0, 0
+			header.Uname, header.Gname = "", ""
+			header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+		}
+
+		err = tw.WriteHeader(header)
+		if err != nil {
+			return errors.Annotatef(err, "could not add %s to tar archive", entry.Path())
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		return entry.File().WithFileReadOnly(func(f afero.File) error {
+			_, err := io.Copy(tw, f)
+			return err
+		})
+	})
+	if err != nil {
+		_ = tw.Close()
+		return err
+	}
+	return tw.Close()
+}
+
+// TarEntries lists the entries of this tar archive without extracting it.
+func (x File) TarEntries() ([]ArchiveEntry, error) {
+	entries := make([]ArchiveEntry, 0)
+	err := x.withTarReader(func(header *tar.Header, _ io.Reader) error {
+		entry := ArchiveEntry{
+			Name:    header.Name,
+			Size:    header.Size,
+			Mode:    header.FileInfo().Mode(),
+			ModTime: header.ModTime,
+		}
+		if entry.IsSymlink() {
+			entry.LinkTarget = header.Linkname
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UntarTo extracts this tar archive into target, which is created if needed. Compressed archives
+// are detected by their file extension like in TarTo. Entries that would be extracted outside of
+// target are rejected with an AssertionError wrapping ErrUnsafeArchivePath before anything is
+// written. Devices and other special files are skipped.
+func (x File) UntarTo(target Dir, opts ExtractOptions) error {
+	// validate all paths first so that a malicious archive does not get extracted partially
+	var declaredSize int64
+	err := x.withTarReader(func(header *tar.Header, _ io.Reader) error {
+		_, err := archiveTargetPath(target, header.Name)
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeLink {
+			_, err = archiveTargetPath(target, header.Linkname)
+			if err != nil {
+				return err
+			}
+		}
+		if header.Typeflag == tar.TypeReg {
+			declaredSize += header.Size
+		}
+		if opts.MaxSize > 0 && declaredSize > opts.MaxSize {
+			return NewAssertionError(ErrArchiveTooLarge, x.path, "extracting %s exceeds the limit of %d bytes", x, opts.MaxSize)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	type extractedDir struct {
+		path    string
+		mode    os.FileMode
+		modTime time.Time
+	}
+	dirs := make([]extractedDir, 0)
+
+	extractor := newArchiveExtractor(target, opts)
+	err = extractor.ensureDir(target.Path(), 0)
+	if err != nil {
+		return err
+	}
+	err = x.withTarReader(func(header *tar.Header, r io.Reader) error {
+		targetPath, err := archiveTargetPath(target, header.Name)
+		if err != nil {
+			return err
+		}
+		info := header.FileInfo()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			dirs = append(dirs, extractedDir{targetPath, info.Mode().Perm(), header.ModTime})
+			return extractor.ensureDir(targetPath, 0)
+		case tar.TypeSymlink:
+			return extractor.symlink(targetPath, header.Linkname, info)
+		case tar.TypeLink:
+			// hard links refer to an entry that was extracted before, copy it
+			linkedPath, err := archiveTargetPath(target, header.Linkname)
+			if err != nil {
+				return err
+			}
+			return extractor.hardlink(targetPath, linkedPath)
+		case tar.TypeReg:
+			return extractor.file(targetPath, info, func() (io.ReadCloser, error) {
+				return io.NopCloser(r), nil
+			})
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	err = extractor.finish()
+	if err != nil {
+		return err
+	}
+
+	// set dir modes and mtimes last, deepest first, because extracting the contents modifies the dirs
+	// and read-only dirs could not be filled otherwise
+	for i := len(dirs) - 1; i >= 0; i-- {
+		// the dir might have existed with other permissions before
+		err = target.fs.Chmod(dirs[i].path, dirs[i].mode)
+		if err != nil {
+			return err
+		}
+		err = target.fs.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withTarReader calls fn for all entries of this tar archive, decompressing it according to its file
+// extension. r reads the content of the current entry.
+func (x File) withTarReader(fn func(header *tar.Header, r io.Reader) error) error {
+	return x.WithFileReadOnly(func(f afero.File) error {
+		var r io.Reader = f
+		if codec, compressed := compressionCodecFor(x); compressed {
+			cr, err := codec.reader(f)
+			if err != nil {
+				return errors.Annotatef(err, "could not read tar archive %s", x)
+			}
+			defer cr.Close()
+			r = cr
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return errors.Annotatef(err, "could not read tar archive %s", x)
+			}
+			err = fn(header, tr)
+			if err != nil {
+				return err
+			}
+		}
+	})
+}
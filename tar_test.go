@@ -0,0 +1,195 @@
+package gofs
+
+import (
+	"archive/tar"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDir_TarTo(t *testing.T) {
+	a := assert.New(t)
+	src := newWalkTestDir(t)
+	a.Nil(src.MustFileAt("large.txt").SetContentString(strings.Repeat("gofs ", 1000)))
+	a.Nil(src.fs.Chmod(src.MustFileAt("a.txt").Path(), 0600))
+	modTime := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	a.Nil(src.fs.Chtimes(src.MustFileAt("a.txt").Path(), modTime, modTime))
+
+	sizes := make(map[string]int64)
+	for _, name := range []string{"archive.tar", "archive.tar.gz", "archive.tgz", "archive.tar.zst", "archive.tar.xz"} {
+		archive := FileWithFs(filepath.Join("/tmp", name), src.fs)
+		a.Nil(src.TarTo(archive, TarOptions{}), name)
+		sizes[name] = archive.Filesize()
+
+		entries, err := archive.TarEntries()
+		a.Nil(err, name)
+		names := make([]string, 0)
+		for _, entry := range entries {
+			names = append(names, entry.Name)
+		}
+		a.Equal([]string{".hidden/", ".hidden/x", "a.txt", "b/", "b/c/", "b/c/deep.txt", "b/z.txt", "d/", "d/e.txt", "large.txt"}, names, name)
+		a.True(entries[0].IsDir())
+		a.Equal(int64(5000), entries[9].Size)
+
+		target := DirWithFs(filepath.Join("/tmp/extracted", name), src.fs)
+		a.Nil(archive.UntarTo(target, ExtractOptions{}), name)
+		a.True(src.MustHash(DirHashOptions{IncludeHidden: true, IncludeMode: true}).Digest.Equals(target.MustHash(DirHashOptions{IncludeHidden: true, IncludeMode: true}).Digest), name)
+		info, err := src.fs.Stat(target.MustFileAt("a.txt").Path())
+		a.Nil(err)
+		a.True(modTime.Equal(info.ModTime()), name)
+	}
+	a.Greater(sizes["archive.tar"], sizes["archive.tar.gz"])
+	a.Greater(sizes["archive.tar"], sizes["archive.tar.zst"])
+	a.Greater(sizes["archive.tar"], sizes["archive.tar.xz"])
+
+	// reproducible archives
+	first := FileWithFs("/tmp/first.tar.gz", src.fs)
+	a.Nil(src.TarTo(first, TarOptions{Reproducible: true}))
+	a.Nil(src.fs.Chtimes(src.MustFileAt("a.txt").Path(), time.Now(), time.Now()))
+	second := FileWithFs("/tmp/second.tar.gz", src.fs)
+	a.Nil(src.TarTo(second, TarOptions{Reproducible: true}))
+	a.Equal(first.MustMd5Hash(), second.MustMd5Hash())
+	entries, err := first.TarEntries()
+	a.Nil(err)
+	a.True(time.Unix(0, 0).Equal(entries[0].ModTime))
+
+	// the archive does not contain itself
+	inside := src.MustFileAt("self.tar")
+	a.Nil(src.TarTo(inside, TarOptions{}))
+	entries, err = inside.TarEntries()
+	a.Nil(err)
+	a.Len(entries, 10)
+}
+
+func TestFile_UntarTo_Unsafe(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+
+	writeTar := func(name string, headers ...*tar.Header) File {
+		f := FileWithFs(filepath.Join("/tmp", name), fs)
+		w, err := f.Writer()
+		a.Nil(err)
+		tw := tar.NewWriter(w)
+		for _, header := range headers {
+			if header.Typeflag == 0 {
+				header.Typeflag = tar.TypeReg
+			}
+			if header.Mode == 0 {
+				header.Mode = 0640
+			}
+			a.Nil(tw.WriteHeader(header))
+			if header.Typeflag == tar.TypeReg {
+				_, err = tw.Write([]byte(strings.Repeat("0", int(header.Size))))
+				a.Nil(err)
+			}
+		}
+		a.Nil(tw.Close())
+		a.Nil(w.Close())
+		return f
+	}
+
+	target := DirWithFs("/tmp/target", fs)
+	for _, name := range []string{"../evil.txt", "/etc/evil", "a/../../evil.txt", "..\\evil.txt"} {
+		err := writeTar("evil.tar", &tar.Header{Name: "ok.txt", Size: 2}, &tar.Header{Name: name, Size: 4}).UntarTo(target, ExtractOptions{})
+		a.ErrorIs(err, ErrUnsafeArchivePath, name)
+		a.True(target.NotExists())
+		a.True(FileWithFs("/tmp/evil.txt", fs).NotExists())
+	}
+	hardlink := writeTar("hardlink.tar", &tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd"})
+	a.ErrorIs(hardlink.UntarTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+	a.True(target.NotExists())
+
+	bomb := writeTar("bomb.tar", &tar.Header{Name: "bomb", Size: 10000})
+	a.ErrorIs(bomb.UntarTo(target, ExtractOptions{MaxSize: 1000}), ErrArchiveTooLarge)
+	a.True(target.MustFileAt("bomb").NotExists())
+	a.Nil(bomb.UntarTo(target, ExtractOptions{MaxSize: 10000}))
+
+	var existsErr *FileExistsError
+	a.ErrorAs(bomb.UntarTo(target, ExtractOptions{Overwrite: OverwriteError}), &existsErr)
+
+	// hard links are extracted as copies
+	linked := writeTar("linked.tar", &tar.Header{Name: "a.txt", Size: 3}, &tar.Header{Name: "b.txt", Typeflag: tar.TypeLink, Linkname: "a.txt"})
+	a.Nil(linked.UntarTo(target, ExtractOptions{}))
+	a.Equal("000", target.MustFileAt("b.txt").MustContentString())
+
+	// modes of read-only dirs are applied after their contents are extracted
+	readOnly := writeTar("readonly.tar", &tar.Header{Name: "ro", Typeflag: tar.TypeDir, Mode: 0555}, &tar.Header{Name: "ro/a.txt", Size: 1})
+	a.Nil(readOnly.UntarTo(target, ExtractOptions{}))
+	a.Equal("0", target.MustFileAt("ro/a.txt").MustContentString())
+	info, err := fs.Stat(target.MustDirAt("ro").Path())
+	a.Nil(err)
+	a.Equal(os.FileMode(0555), info.Mode().Perm())
+
+	// hard links must not point to files that were not extracted from the archive
+	foreign := writeTar("foreign.tar", &tar.Header{Name: "c.txt", Typeflag: tar.TypeLink, Linkname: "bomb"})
+	a.ErrorIs(foreign.UntarTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+	a.True(target.MustFileAt("c.txt").NotExists())
+}
+
+func TestFile_UntarTo_Symlinks(t *testing.T) {
+	a := assert.New(t)
+	src := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.MustFileAt("real.txt").SetContentString("real"))
+	a.Nil(os.Symlink("real.txt", filepath.Join(src.Path(), "link.txt")))
+
+	archive := DirWithFs(t.TempDir(), afero.NewOsFs()).MustFileAt("links.tar.zst")
+	a.Nil(src.TarTo(archive, TarOptions{}))
+	entries, err := archive.TarEntries()
+	a.Nil(err)
+	a.True(entries[0].IsSymlink())
+	a.Equal("real.txt", entries[0].LinkTarget)
+
+	target := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(archive.UntarTo(target, ExtractOptions{}))
+	link, err := os.Readlink(target.MustFileAt("link.txt").Path())
+	a.Nil(err)
+	a.Equal("real.txt", link)
+
+	evil := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(os.Symlink("../../etc/passwd", filepath.Join(evil.Path(), "evil")))
+	a.Nil(evil.TarTo(archive, TarOptions{}))
+	a.ErrorIs(archive.UntarTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+}
+
+func TestFile_UntarTo_SymlinkChain(t *testing.T) {
+	a := assert.New(t)
+	base := DirWithFs(t.TempDir(), afero.NewOsFs())
+	archive := base.MustFileAt("chain.tar")
+	w, err := archive.Writer()
+	a.Nil(err)
+	tw := tar.NewWriter(w)
+	a.Nil(tw.WriteHeader(&tar.Header{Name: "d", Typeflag: tar.TypeSymlink, Linkname: ".", Mode: 0777}))
+	a.Nil(tw.WriteHeader(&tar.Header{Name: "d/e", Typeflag: tar.TypeSymlink, Linkname: "..", Mode: 0777}))
+	a.Nil(tw.WriteHeader(&tar.Header{Name: "d/e/evil.txt", Typeflag: tar.TypeReg, Mode: 0640, Size: 4}))
+	_, err = tw.Write([]byte("evil"))
+	a.Nil(err)
+	a.Nil(tw.Close())
+	a.Nil(w.Close())
+
+	target := base.MustDirAt("target")
+	a.ErrorIs(archive.UntarTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+	a.True(base.MustFileAt("evil.txt").NotExists())
+	a.True(target.MustFileAt("evil.txt").NotExists())
+}
+
+func TestFile_UntarTo_HardlinkThroughSymlink(t *testing.T) {
+	a := assert.New(t)
+	base := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(base.MustFileAt("secret.txt").SetContentString("secret"))
+	archive := base.MustFileAt("hardlink.tar")
+	w, err := archive.Writer()
+	a.Nil(err)
+	tw := tar.NewWriter(w)
+	a.Nil(tw.WriteHeader(&tar.Header{Name: "up", Typeflag: tar.TypeSymlink, Linkname: "..", Mode: 0777}))
+	a.Nil(tw.WriteHeader(&tar.Header{Name: "copy.txt", Typeflag: tar.TypeLink, Linkname: "up/secret.txt"}))
+	a.Nil(tw.Close())
+	a.Nil(w.Close())
+
+	target := base.MustDirAt("target")
+	a.ErrorIs(archive.UntarTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+	a.True(target.MustFileAt("copy.txt").NotExists())
+}
@@ -0,0 +1,95 @@
+package gofs
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// TempDir creates a new directory in the default directory for temporary files of the OS. The name
+// is generated from pattern by replacing the last "*" with a random string, or by appending it if
+// there is no "*". The caller is responsible for removing the dir, e.g. by deferring Cleanup.
+func TempDir(pattern string) (Dir, error) {
+	return TempDirWithFs(pattern, afero.NewOsFs())
+}
+
+// TempDirWithFs creates a new temporary directory like TempDir on fs.
+func TempDirWithFs(pattern string, fs afero.Fs) (Dir, error) {
+	parent := os.TempDir()
+	err := fs.MkdirAll(parent, 0750)
+	if err != nil {
+		return Dir{}, errors.Annotatef(err, "could not create temp dir root %s", parent)
+	}
+
+	for i := 0; i < 10000; i++ {
+		dirPath := filepath.Join(parent, tempName(pattern))
+		err = fs.Mkdir(dirPath, 0700)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return Dir{}, errors.Annotatef(err, "could not create temp dir")
+		}
+		return DirWithFs(dirPath, fs), nil
+	}
+	return Dir{}, errors.Errorf("could not find an unused name for temp dir %s", pattern)
+}
+
+func MustTempDir(pattern string) Dir {
+	dir, err := TempDir(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}
+
+// TempFile creates a new empty file in dir. The filename is generated from pattern like for TempDir.
+// The file is created on the filesystem of dir with permissions 0600.
+func TempFile(dir Dir, pattern string) (File, error) {
+	for i := 0; i < 10000; i++ {
+		file := FileAtDir(dir, tempName(pattern))
+		f, err := dir.fs.OpenFile(file.Path(), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return File{}, errors.Annotatef(err, "could not create temp file in %s", dir)
+		}
+		return file, f.Close()
+	}
+	return File{}, errors.Errorf("could not find an unused name for temp file %s in %s", pattern, dir)
+}
+
+func MustTempFile(dir Dir, pattern string) File {
+	file, err := TempFile(dir, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+// Cleanup removes this dir with all of its contents. It is meant to be deferred after creating a
+// temporary dir. A dir that does not exist (anymore) is no error.
+func (x Dir) Cleanup() error {
+	return x.Remove()
+}
+
+// Cleanup removes this file. It is meant to be deferred after creating a temporary file. A file
+// that does not exist (anymore) is no error.
+func (x File) Cleanup() error {
+	return x.Remove()
+}
+
+// tempName returns a random name according to pattern.
+func tempName(pattern string) string {
+	random := strconv.FormatUint(uint64(rand.Uint32()), 36)
+	if pos := strings.LastIndex(pattern, "*"); pos != -1 {
+		return pattern[:pos] + random + pattern[pos+1:]
+	}
+	return pattern + random
+}
@@ -0,0 +1,62 @@
+package gofs
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTempDir(t *testing.T) {
+	a := assert.New(t)
+
+	d, err := TempDir("gofs-*-test")
+	a.Nil(err)
+	defer d.Cleanup()
+	a.True(d.Exists())
+	a.True(strings.HasPrefix(d.Name(), "gofs-"))
+	a.True(strings.HasSuffix(d.Name(), "-test"))
+	a.Equal(os.TempDir(), d.Parent().Path())
+
+	f, err := TempFile(d, "*.log")
+	a.Nil(err)
+	a.True(f.Exists())
+	a.True(f.IsEmpty())
+	a.True(f.HasExtension(ExtLog))
+
+	a.Nil(d.Cleanup())
+	a.True(d.NotExists())
+	a.Nil(d.Cleanup())
+}
+
+func TestTempDirWithFs(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+
+	d, err := TempDirWithFs("gofs", fs)
+	a.Nil(err)
+	a.True(d.Exists())
+	a.False(DirAt(d.Path()).Exists())
+
+	f := MustTempFile(d, "data")
+	a.True(f.Exists())
+	a.Nil(f.Cleanup())
+	a.True(f.NotExists())
+}
+
+func TestDir_Clear(t *testing.T) {
+	a := assert.New(t)
+	d := MustTempDir("gofs")
+	defer d.Cleanup()
+	a.Nil(d.MustFileAt("file").SetContentString("a"))
+	a.Nil(d.MustDirAt("sub").Ensure(0750))
+
+	// clearing a dir on another fs at the same path does not touch this one
+	a.Nil(d.WithFs(afero.NewMemMapFs()).MustEnsure(0750).Clear())
+	a.False(d.IsEmpty())
+
+	a.Nil(d.Clear())
+	a.True(d.Exists())
+	a.True(d.IsEmpty())
+}
@@ -0,0 +1,101 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+)
+
+// TypedFile binds a File to a Go type and a Codec, which is handy for small state and config files.
+type TypedFile[T any] struct {
+	file         File
+	codec        Codec
+	defaultValue T
+}
+
+// NewTypedFile returns a TypedFile for file using the codec registered for its file extension.
+// defaultValue is returned by Load while the file does not exist.
+func NewTypedFile[T any](file File, defaultValue T) (TypedFile[T], error) {
+	codec, err := file.Codec()
+	if err != nil {
+		return TypedFile[T]{}, err
+	}
+	return NewTypedFileWithCodec(file, codec, defaultValue), nil
+}
+
+// NewTypedFileWithCodec returns a TypedFile for file using codec. defaultValue is returned by Load
+// while the file does not exist.
+func NewTypedFileWithCodec[T any](file File, codec Codec, defaultValue T) TypedFile[T] {
+	return TypedFile[T]{
+		file:         file,
+		codec:        codec,
+		defaultValue: defaultValue,
+	}
+}
+
+// File returns the underlying file.
+func (x TypedFile[T]) File() File {
+	return x.file
+}
+
+// Load decodes the content of the file. If it does not exist, a copy of the default value is
+// returned, so modifying the result never changes the default.
+func (x TypedFile[T]) Load() (T, error) {
+	var result T
+	exists, err := x.file.ExistsE()
+	if err != nil {
+		return result, err
+	}
+	if !exists {
+		return x.defaultCopy()
+	}
+	err = x.file.DecodeWith(x.codec, &result)
+	return result, err
+}
+
+func (x TypedFile[T]) MustLoad() T {
+	value, err := x.Load()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Save replaces the content of the file with the encoded value. The file is written atomically, so
+// concurrent readers never see a partial state.
+func (x TypedFile[T]) Save(value T) error {
+	content, err := x.codec.Encode(value)
+	if err != nil {
+		return errors.Annotatef(err, "could not encode %s for %s", x.codec.Name(), x.file)
+	}
+	return x.file.AtomicSetContent(content)
+}
+
+// Update loads the value, passes it to fn and saves it afterwards unless fn returns an error. The
+// whole read-modify-write cycle holds an exclusive lock on the file (see File.Lock), so concurrent
+// updates are not lost, also across processes.
+func (x TypedFile[T]) Update(fn func(value *T) error) error {
+	return x.file.WithLock(func() error {
+		value, err := x.Load()
+		if err != nil {
+			return err
+		}
+		err = fn(&value)
+		if err != nil {
+			return err
+		}
+		return x.Save(value)
+	})
+}
+
+// defaultCopy returns a deep copy of the default value by encoding and decoding it.
+func (x TypedFile[T]) defaultCopy() (T, error) {
+	var result T
+	content, err := x.codec.Encode(x.defaultValue)
+	if err != nil {
+		return result, errors.Annotatef(err, "could not encode default value of %s", x.file)
+	}
+	err = x.codec.Decode(content, &result)
+	if err != nil {
+		return result, errors.Annotatef(err, "could not decode default value of %s", x.file)
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package gofs
+
+import (
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type typedFileTestState struct {
+	Counter int               `json:"counter" yaml:"counter"`
+	Seen    map[string]bool   `json:"seen" yaml:"seen"`
+	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+func TestTypedFile(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	defaultState := typedFileTestState{Seen: map[string]bool{}}
+
+	state, err := NewTypedFile(FileWithFs("/tmp/state.yaml", fs), defaultState)
+	a.Nil(err)
+	loaded, err := state.Load()
+	a.Nil(err)
+	a.Equal(defaultState, loaded)
+	a.True(state.File().NotExists())
+
+	// the default value is not changed by modifying loaded values
+	loaded.Seen["a"] = true
+	a.Empty(defaultState.Seen)
+	a.Empty(state.MustLoad().Seen)
+
+	a.Nil(state.Save(loaded))
+	a.Equal("counter: 0\nseen:\n  a: true\n", state.File().MustContentString())
+	a.Equal(loaded, state.MustLoad())
+
+	a.Nil(state.Update(func(value *typedFileTestState) error {
+		value.Counter = 42
+		return nil
+	}))
+	a.Equal(42, state.MustLoad().Counter)
+
+	// failing updates are not saved
+	a.Error(state.Update(func(value *typedFileTestState) error {
+		value.Counter = 0
+		return errors.New("failed")
+	}))
+	a.Equal(42, state.MustLoad().Counter)
+
+	_, err = NewTypedFile(FileWithFs("/tmp/state.unknown", fs), defaultState)
+	a.Error(err)
+
+	a.Nil(state.File().SetContentString("counter: [invalid"))
+	_, err = state.Load()
+	a.Error(err)
+}
+
+func TestTypedFile_ConcurrentUpdate(t *testing.T) {
+	a := assert.New(t)
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every update uses its own file and filesystem instance
+			state := NewTypedFileWithCodec(FileAt(filepath.Join(dir, "counter.json")), JSONCodec{}, typedFileTestState{})
+			a.Nil(state.Update(func(value *typedFileTestState) error {
+				value.Counter++
+				return nil
+			}))
+		}()
+	}
+	wg.Wait()
+
+	state := NewTypedFileWithCodec(FileAt(filepath.Join(dir, "counter.json")), JSONCodec{}, typedFileTestState{})
+	a.Equal(20, state.MustLoad().Counter)
+}
@@ -0,0 +1,259 @@
+package gofs
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/spf13/afero"
+)
+
+// ZipOptions configures creating zip archives.
+type ZipOptions struct {
+	CompressionLevel CompressionLevel
+	// ModTime is used as modification time of all entries if set, which makes archives of equal
+	// contents byte for byte identical.
+	ModTime time.Time
+	// Ignore selects ignore rules, ignored files and dirs are not archived.
+	Ignore IgnoreOptions
+}
+
+// ExtractOptions configures extracting archives.
+//
+// The zero value does not limit the extracted size. Callers extracting untrusted archives must set
+// MaxSize, otherwise a decompression bomb can fill the disk.
+type ExtractOptions struct {
+	// Overwrite defines how existing files in the target dir are handled.
+	Overwrite OverwritePolicy
+	// MaxSize limits the total number of bytes extracted to protect against decompression bombs. 0
+	// means no limit, so set it for untrusted input.
+	MaxSize int64
+	// DirPermissions is used for dirs without permissions in the archive. Defaults to 0750.
+	DirPermissions os.FileMode
+}
+
+// ZipTo writes the contents of this dir to the zip archive target. Entries are added in lexical
+// order with paths relative to this dir. Symlinks are stored as symlinks if the filesystem supports
+// reading them.
+func (x Dir) ZipTo(target File, opts ZipOptions) error {
+	w, err := target.fs.OpenFile(target.Path(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, target.createPermissions)
+	if err != nil {
+		return errors.Annotatef(err, "could not create zip archive %s", target)
+	}
+
+	err = x.writeZip(w, target, opts)
+	closeErr := w.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (x Dir) writeZip(w io.Writer, target File, opts ZipOptions) error {
+	zw := zip.NewWriter(w)
+	method := zip.Deflate
+	switch opts.CompressionLevel {
+	case CompressionNone:
+		method = zip.Store
+	case CompressionFastest:
+		zw.RegisterCompressor(zip.Deflate, flateCompressor(flate.BestSpeed))
+	case CompressionBest:
+		zw.RegisterCompressor(zip.Deflate, flateCompressor(flate.BestCompression))
+	}
+
+	err := x.Walk(WalkOptions{Ignore: opts.Ignore}, func(entry WalkEntry) error {
+		if isArchiveOf(target, x, entry.Path()) {
+			return nil
+		}
+
+		header, err := zip.FileInfoHeader(entry.Info())
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(entry.RelativePath())
+		header.Method = method
+		if !opts.ModTime.IsZero() {
+			header.Modified = opts.ModTime.UTC()
+		}
+		if entry.IsDir() {
+			header.Name += "/"
+			header.Method = zip.Store
+		}
+
+		isSymlink := entry.Info().Mode()&os.ModeSymlink != 0
+		var linkTarget string
+		if isSymlink {
+			linkTarget, err = readSymlink(x.fs, entry.Path())
+			if err != nil {
+				// the filesystem can not read symlinks, store what it points to
+				isSymlink = false
+				header.SetMode(0640)
+			}
+		}
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return errors.Annotatef(err, "could not add %s to zip archive", entry.Path())
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if isSymlink {
+			_, err = io.WriteString(fw, linkTarget)
+			return err
+		}
+		return entry.File().WithFileReadOnly(func(f afero.File) error {
+			_, err := io.Copy(fw, f)
+			return err
+		})
+	})
+	if err != nil {
+		_ = zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ZipEntries lists the entries of this zip archive without extracting it.
+func (x File) ZipEntries() ([]ArchiveEntry, error) {
+	entries := make([]ArchiveEntry, 0)
+	err := x.withZipReader(func(zr *zip.Reader) error {
+		for _, zf := range zr.File {
+			entry := ArchiveEntry{
+				Name:    zf.Name,
+				Size:    int64(zf.UncompressedSize64),
+				Mode:    zf.Mode(),
+				ModTime: zf.Modified,
+			}
+			if entry.IsSymlink() {
+				linkTarget, err := readZipFile(zf, 4096)
+				if err != nil {
+					return err
+				}
+				entry.LinkTarget = string(linkTarget)
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UnzipTo extracts this zip archive into target, which is created if needed. Entries that would be
+// extracted outside of target are rejected with an AssertionError wrapping ErrUnsafeArchivePath
+// before anything is written.
+func (x File) UnzipTo(target Dir, opts ExtractOptions) error {
+	return x.withZipReader(func(zr *zip.Reader) error {
+		// validate all paths first so that a malicious archive does not get extracted partially
+		targetPaths := make([]string, len(zr.File))
+		for i, zf := range zr.File {
+			targetPath, err := archiveTargetPath(target, zf.Name)
+			if err != nil {
+				return err
+			}
+			targetPaths[i] = targetPath
+		}
+
+		type extractedDir struct {
+			path    string
+			mode    os.FileMode
+			modTime time.Time
+		}
+		dirs := make([]extractedDir, 0)
+
+		extractor := newArchiveExtractor(target, opts)
+		err := extractor.ensureDir(target.Path(), 0)
+		if err != nil {
+			return err
+		}
+		for i, zf := range zr.File {
+			info := zf.FileInfo()
+			switch {
+			case info.IsDir():
+				dirs = append(dirs, extractedDir{targetPaths[i], info.Mode().Perm(), zf.Modified})
+				err = extractor.ensureDir(targetPaths[i], 0)
+			case info.Mode()&os.ModeSymlink != 0:
+				var linkTarget []byte
+				linkTarget, err = readZipFile(zf, 4096)
+				if err == nil {
+					err = extractor.symlink(targetPaths[i], string(linkTarget), info)
+				}
+			default:
+				err = extractor.file(targetPaths[i], info, func() (io.ReadCloser, error) {
+					return zf.Open()
+				})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		err = extractor.finish()
+		if err != nil {
+			return err
+		}
+
+		// set dir modes and mtimes last, deepest first, because extracting the contents modifies the
+		// dirs and read-only dirs could not be filled otherwise
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if dirs[i].mode != 0 {
+				err = target.fs.Chmod(dirs[i].path, dirs[i].mode)
+				if err != nil {
+					return err
+				}
+			}
+			if dirs[i].modTime.IsZero() {
+				continue
+			}
+			err = target.fs.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (x File) withZipReader(fn func(zr *zip.Reader) error) error {
+	return x.WithFileReadOnly(func(f afero.File) error {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(f, info.Size())
+		if err != nil {
+			return errors.Annotatef(err, "could not read zip archive %s", x)
+		}
+		return fn(zr)
+	})
+}
+
+func readZipFile(zf *zip.File, limit int64) ([]byte, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
+
+func flateCompressor(level int) zip.Compressor {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	}
+}
+
+// readSymlink returns the target of the symlink at linkPath.
+func readSymlink(fs afero.Fs, linkPath string) (string, error) {
+	reader, ok := fs.(afero.LinkReader)
+	if !ok {
+		return "", errors.NotSupportedf("reading symlinks on %T", fs)
+	}
+	return reader.ReadlinkIfPossible(linkPath)
+}
@@ -0,0 +1,171 @@
+package gofs
+
+import (
+	"archive/zip"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDir_ZipTo(t *testing.T) {
+	a := assert.New(t)
+	src := newWalkTestDir(t)
+	a.Nil(src.MustFileAt("large.txt").SetContentString(strings.Repeat("gofs ", 1000)))
+	archive := FileWithFs("/tmp/archive.zip", src.fs)
+
+	a.Nil(src.ZipTo(archive, ZipOptions{}))
+	entries, err := archive.ZipEntries()
+	a.Nil(err)
+	names := make([]string, 0)
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	a.Equal([]string{".hidden/", ".hidden/x", "a.txt", "b/", "b/c/", "b/c/deep.txt", "b/z.txt", "d/", "d/e.txt", "large.txt"}, names)
+	a.True(entries[0].IsDir())
+	a.Equal(int64(5000), entries[9].Size)
+
+	target := DirWithFs("/tmp/unzipped", src.fs)
+	a.Nil(archive.UnzipTo(target, ExtractOptions{}))
+	a.True(src.MustHash(DirHashOptions{IncludeHidden: true}).Digest.Equals(target.MustHash(DirHashOptions{IncludeHidden: true}).Digest))
+
+	// compression levels
+	stored := FileWithFs("/tmp/stored.zip", src.fs)
+	a.Nil(src.ZipTo(stored, ZipOptions{CompressionLevel: CompressionNone}))
+	best := FileWithFs("/tmp/best.zip", src.fs)
+	a.Nil(src.ZipTo(best, ZipOptions{CompressionLevel: CompressionBest}))
+	a.Greater(stored.Filesize(), best.Filesize())
+
+	// reproducible archives
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := FileWithFs("/tmp/first.zip", src.fs)
+	a.Nil(src.ZipTo(first, ZipOptions{ModTime: modTime}))
+	a.Nil(src.fs.Chtimes(src.MustFileAt("a.txt").Path(), time.Now(), time.Now()))
+	second := FileWithFs("/tmp/second.zip", src.fs)
+	a.Nil(src.ZipTo(second, ZipOptions{ModTime: modTime}))
+	a.Equal(first.MustMd5Hash(), second.MustMd5Hash())
+
+	// the archive does not contain itself
+	inside := src.MustFileAt("self.zip")
+	a.Nil(src.ZipTo(inside, ZipOptions{}))
+	entries, err = inside.ZipEntries()
+	a.Nil(err)
+	a.Len(entries, 10)
+}
+
+func TestFile_UnzipTo_Unsafe(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+
+	writeZip := func(name string, entries map[string]string) File {
+		f := FileWithFs(filepath.Join("/tmp", name), fs)
+		w, err := f.Writer()
+		a.Nil(err)
+		zw := zip.NewWriter(w)
+		for entryName, content := range entries {
+			fw, err := zw.Create(entryName)
+			a.Nil(err)
+			_, err = fw.Write([]byte(content))
+			a.Nil(err)
+		}
+		a.Nil(zw.Close())
+		a.Nil(w.Close())
+		return f
+	}
+
+	target := DirWithFs("/tmp/target", fs)
+	for _, name := range []string{"../evil.txt", "/etc/evil", "a/../../evil.txt", "..\\evil.txt"} {
+		err := writeZip("evil.zip", map[string]string{"ok.txt": "ok", name: "evil"}).UnzipTo(target, ExtractOptions{})
+		a.ErrorIs(err, ErrUnsafeArchivePath, name)
+		a.True(target.NotExists())
+		a.True(FileWithFs("/tmp/evil.txt", fs).NotExists())
+	}
+
+	bomb := writeZip("bomb.zip", map[string]string{"bomb": strings.Repeat("0", 10000)})
+	a.ErrorIs(bomb.UnzipTo(target, ExtractOptions{MaxSize: 1000}), ErrArchiveTooLarge)
+	a.True(target.MustFileAt("bomb").NotExists())
+	a.Nil(bomb.UnzipTo(target, ExtractOptions{MaxSize: 10000}))
+
+	var existsErr *FileExistsError
+	a.ErrorAs(bomb.UnzipTo(target, ExtractOptions{Overwrite: OverwriteError}), &existsErr)
+}
+
+func TestFile_UnzipTo_Symlinks(t *testing.T) {
+	a := assert.New(t)
+	src := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(src.MustFileAt("real.txt").SetContentString("real"))
+	a.Nil(os.Symlink("real.txt", filepath.Join(src.Path(), "link.txt")))
+
+	archive := DirWithFs(t.TempDir(), afero.NewOsFs()).MustFileAt("links.zip")
+	a.Nil(src.ZipTo(archive, ZipOptions{}))
+	entries, err := archive.ZipEntries()
+	a.Nil(err)
+	a.True(entries[0].IsSymlink())
+	a.Equal("real.txt", entries[0].LinkTarget)
+
+	target := DirWithFs(t.TempDir(), afero.NewOsFs())
+	a.Nil(archive.UnzipTo(target, ExtractOptions{}))
+	link, err := os.Readlink(target.MustFileAt("link.txt").Path())
+	a.Nil(err)
+	a.Equal("real.txt", link)
+}
+
+func TestFile_UnzipTo_SymlinkChain(t *testing.T) {
+	a := assert.New(t)
+	base := DirWithFs(t.TempDir(), afero.NewOsFs())
+	archive := base.MustFileAt("chain.zip")
+	w, err := archive.Writer()
+	a.Nil(err)
+	zw := zip.NewWriter(w)
+	for _, entry := range []struct{ name, content string }{{"d", "."}, {"d/e", ".."}} {
+		header := &zip.FileHeader{Name: entry.name}
+		header.SetMode(os.ModeSymlink | 0777)
+		fw, err := zw.CreateHeader(header)
+		a.Nil(err)
+		_, err = fw.Write([]byte(entry.content))
+		a.Nil(err)
+	}
+	fw, err := zw.Create("d/e/evil.txt")
+	a.Nil(err)
+	_, err = fw.Write([]byte("evil"))
+	a.Nil(err)
+	a.Nil(zw.Close())
+	a.Nil(w.Close())
+
+	target := base.MustDirAt("target")
+	a.ErrorIs(archive.UnzipTo(target, ExtractOptions{}), ErrUnsafeArchivePath)
+	a.True(base.MustFileAt("evil.txt").NotExists())
+	a.True(target.MustFileAt("evil.txt").NotExists())
+}
+
+func TestFile_UnzipTo_DirMetadata(t *testing.T) {
+	a := assert.New(t)
+	fs := afero.NewMemMapFs()
+	archive := FileWithFs("/tmp/dirs.zip", fs)
+	w, err := archive.Writer()
+	a.Nil(err)
+	zw := zip.NewWriter(w)
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	header := &zip.FileHeader{Name: "ro/", Modified: modTime}
+	header.SetMode(os.ModeDir | 0555)
+	_, err = zw.CreateHeader(header)
+	a.Nil(err)
+	fw, err := zw.Create("ro/a.txt")
+	a.Nil(err)
+	_, err = fw.Write([]byte("a"))
+	a.Nil(err)
+	a.Nil(zw.Close())
+	a.Nil(w.Close())
+
+	// modes of read-only dirs are applied after their contents are extracted
+	target := DirWithFs("/tmp/target", fs)
+	a.Nil(archive.UnzipTo(target, ExtractOptions{}))
+	a.Equal("a", target.MustFileAt("ro/a.txt").MustContentString())
+	info, err := fs.Stat(target.MustDirAt("ro").Path())
+	a.Nil(err)
+	a.Equal(os.FileMode(0555), info.Mode().Perm())
+	a.True(modTime.Equal(info.ModTime()))
+}